@@ -1,37 +1,216 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/evisdrenova/axon-server/config"
 	handlers "github.com/evisdrenova/axon-server/handlers"
+	"github.com/evisdrenova/axon-server/handlers/auth"
 	"github.com/evisdrenova/axon-server/parser"
+	"github.com/evisdrenova/axon-server/registry"
 	"github.com/evisdrenova/axon-server/server"
 )
 
 func main() {
+	configPath := flag.String("config", "", "path to a YAML or JSON config file")
+	serverSelector := flag.String("server", "", "index or description (e.g. staging) of the server to send requests to")
+	baseURL := flag.String("base-url", "", "URL to send requests to instead of the servers declared in the spec")
+	serverVariables := keyValueFlag{}
+	flag.Var(serverVariables, "server-var", "override a server URL variable as name=value, can be repeated")
+	specHeaders := keyValueFlag{}
+	flag.Var(specHeaders, "spec-header", "header to send when fetching a spec from a URL as name=value, can be repeated")
+	uploadDirs := &stringListFlag{}
+	flag.Var(uploadDirs, "upload-dir", "directory that files to upload may be read from, can be repeated")
+	dryRun := flag.Bool("dry-run", false, "return the requests tools would send instead of sending them")
+	confirmUnsafe := flag.Bool("confirm-unsafe", false, "make the user confirm calls of operations that aren't GET, HEAD, OPTIONS or TRACE")
+	readOnly := flag.Bool("read-only", false, "only register operations that are GET, HEAD, OPTIONS or TRACE")
+	list := flag.Bool("list", false, "list the tools that would be exposed and exit")
+	watch := flag.Bool("watch", false, "reload specs when they change and update the tools while the server runs")
+	strict := flag.Bool("strict", false, "refuse specs that don't validate instead of exposing the operations that can be converted")
+	includeTags := &stringListFlag{}
+	flag.Var(includeTags, "include-tag", "only expose operations with this tag, can be repeated")
+	excludeTags := &stringListFlag{}
+	flag.Var(excludeTags, "exclude-tag", "leave out operations with this tag, can be repeated")
+	includePaths := &stringListFlag{}
+	flag.Var(includePaths, "include-path", "only expose operations whose path matches this glob, e.g. /pets/**, can be repeated")
+	excludePaths := &stringListFlag{}
+	flag.Var(excludePaths, "exclude-path", "leave out operations whose path matches this glob, can be repeated")
+	includeMethods := &stringListFlag{}
+	flag.Var(includeMethods, "include-method", "only expose operations with this HTTP method, can be repeated")
+	excludeMethods := &stringListFlag{}
+	flag.Var(excludeMethods, "exclude-method", "leave out operations with this HTTP method, can be repeated")
+	includePattern := flag.String("include-pattern", "", "only expose operations whose operationId matches this regular expression")
+	excludePattern := flag.String("exclude-pattern", "", "leave out operations whose operationId matches this regular expression")
+	excludeDeprecated := flag.Bool("exclude-deprecated", false, "leave out deprecated operations")
+	previewTool := flag.Bool("preview-tool", false, "add a "+handlers.PreviewToolName+" tool that shows the request of any other tool without sending it")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] [prefix=]<path-to-api-spec>...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s --config <path-to-config>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var cfg *config.Config
+	var err error
+	if *configPath != "" {
+		if flag.NArg() != 0 {
+			log.Fatalf("Spec arguments can't be combined with --config, list the specs under specs in the config file")
+		}
+		if conflicting := specFlags(); len(conflicting) > 0 {
+			log.Fatalf("%s can't be combined with --config, set them in the config file instead", strings.Join(conflicting, ", "))
+		}
+		cfg, err = config.Load(*configPath)
+		if err != nil {
+			log.Fatalf("Unable to load config: %v", err)
+		}
+	} else {
+		if flag.NArg() == 0 {
+			flag.Usage()
+			os.Exit(1)
+		}
+		cfg = &config.Config{UploadDirs: *uploadDirs}
+		for _, arg := range flag.Args() {
+			prefix, path := splitSpecArg(arg)
+			cfg.Specs = append(cfg.Specs, config.SpecConfig{
+				Path:              path,
+				Prefix:            prefix,
+				BaseURL:           *baseURL,
+				Server:            *serverSelector,
+				ServerVariables:   serverVariables,
+				SpecHeaders:       specHeaders,
+				IncludePattern:    *includePattern,
+				ExcludePattern:    *excludePattern,
+				IncludeTags:       *includeTags,
+				ExcludeTags:       *excludeTags,
+				IncludePaths:      *includePaths,
+				ExcludePaths:      *excludePaths,
+				IncludeMethods:    *includeMethods,
+				ExcludeMethods:    *excludeMethods,
+				ExcludeDeprecated: *excludeDeprecated,
+			})
+		}
+		cfg.ApplyDefaults()
+		if err := cfg.Validate(); err != nil {
+			log.Fatalf("Invalid arguments: %v", err)
+		}
+	}
+	cfg.DryRun = cfg.DryRun || *dryRun
+	cfg.PreviewTool = cfg.PreviewTool || *previewTool
+	cfg.ConfirmUnsafe = cfg.ConfirmUnsafe || *confirmUnsafe
+	cfg.ReadOnly = cfg.ReadOnly || *readOnly
+	cfg.Strict = cfg.Strict || *strict
+	cfg.Watch.Enabled = cfg.Watch.Enabled || *watch
+
+	transport, err := handlers.NewTransport(handlers.TransportConfig{
+		ProxyURL:           cfg.HTTP.Proxy,
+		CAFile:             cfg.HTTP.CAFile,
+		CertFile:           cfg.HTTP.ClientCert,
+		KeyFile:            cfg.HTTP.ClientKey,
+		InsecureSkipVerify: cfg.HTTP.InsecureSkipVerify,
+	})
+	if err != nil {
+		log.Fatalf("Unable to set up the HTTP transport: %v", err)
+	}
+	if cfg.HTTP.InsecureSkipVerify {
+		log.Printf("TLS certificate verification is disabled, don't use insecure_skip_verify outside of local development")
+	}
+
+	if cfg.SpecCacheDir == "" {
+		if cacheDir, err := os.UserCacheDir(); err == nil {
+			cfg.SpecCacheDir = filepath.Join(cacheDir, "axon", "specs")
+		}
+	}
+
+	if *list {
+		if err := listTools(os.Stdout, cfg, transport); err != nil {
+			log.Fatalf("Unable to list tools: %v", err)
+		}
+		return
+	}
+
+	// Credentials from the file named by AXON_CREDENTIALS_FILE fill in whatever the config doesn't set
+	if credentialsPath := os.Getenv("AXON_CREDENTIALS_FILE"); credentialsPath != "" {
+		credentials, err := auth.LoadCredentialsFile(credentialsPath)
+		if err != nil {
+			log.Fatalf("Unable to load credentials: %v", err)
+		}
+		for i := range cfg.Specs {
+			if cfg.Specs[i].Auth == nil {
+				cfg.Specs[i].Auth = make(map[string]auth.Credentials)
+			}
+			for name, creds := range credentials {
+				if _, ok := cfg.Specs[i].Auth[name]; !ok {
+					cfg.Specs[i].Auth[name] = creds
+				}
+			}
+		}
+	}
+
+	// resources hold the spec diagnostics and, with stash_responses, responses that don't fit in a tool result
 	s := server.NewMCPServer(
-		"axon-server",
-		"0.0.1",
+		cfg.Server.Name,
+		cfg.Server.Version,
+		server.WithArgumentValidation(),
+		server.WithResourceCapabilities(false, true),
 	)
+	report := handlers.NewDiagnosticsReport(s)
 
-	if len(os.Args) != 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <path-to-api-spec>\n", os.Args[0])
-		os.Exit(1)
+	handlerOptions := []handlers.HandlerOption{handlers.WithTransport(transport), handlers.WithDefaultHeaders(cfg.HTTP.Headers)}
+	if cfg.MaxResponseSize != 0 {
+		handlerOptions = append(handlerOptions, handlers.WithMaxResponseSize(cfg.MaxResponseSize))
+	}
+	if cfg.StashResponses {
+		handlerOptions = append(handlerOptions, handlers.WithResponseStore(handlers.NewResponseStore(s, 0)))
+	}
+	if cfg.ValidateResponses {
+		handlerOptions = append(handlerOptions, handlers.WithResponseValidation())
+	}
+	if cfg.DryRun {
+		log.Printf("Dry run, tools return the requests they build instead of sending them")
+		handlerOptions = append(handlerOptions, handlers.WithDryRun())
 	}
 
-	specPath := os.Args[1]
+	var gate *handlers.ConfirmationGate
+	// a dry run never sends anything, confirmation tokens would only be redeemable once it is over
+	if cfg.ConfirmUnsafe && !cfg.ReadOnly && !cfg.DryRun {
+		gate = handlers.NewConfirmationGate(0)
+	}
 
-	// Parse the spec
-	tools, err := parser.ParseSpecRouter(specPath)
-	if err != nil {
-		log.Fatalf("Unable to convert spec: %v", err)
+	var registered []*registry.Spec
+	for _, spec := range cfg.Specs {
+		specTools, err := registerSpec(s, cfg, spec, transport, gate, report, handlerOptions)
+		if err != nil {
+			log.Fatalf("Unable to convert spec %s: %v", spec.Path, err)
+		}
+		registered = append(registered, specTools)
 	}
 
-	// Register tools with the server
-	for _, tool := range tools {
-		s.AddTool(tool, handlers.CreateOpenAPIMCPToolHandler(tool))
+	if cfg.PreviewTool {
+		if s.HasTool(handlers.PreviewToolName) {
+			log.Fatalf("Unable to add the %s tool, a spec already has a tool with that name", handlers.PreviewToolName)
+		}
+		s.AddTool(handlers.NewPreviewTool(s))
+	}
+
+	if cfg.Watch.Enabled {
+		go registry.Watch(registered, cfg.Watch)
+	}
+
+	if cfg.Transport.Type == config.TransportSSE {
+		log.Printf("Starting SSE server on %s", cfg.Transport.Address)
+		if err := server.NewSSEServer(s, cfg.Transport.BaseURL).Start(cfg.Transport.Address); err != nil {
+			log.Fatalf("Server error: %v", err)
+		}
+		return
 	}
 
 	// Start the stdio server
@@ -39,3 +218,224 @@ func main() {
 		fmt.Printf("Server error: %v\n", err)
 	}
 }
+
+// Parses a spec and returns the tools it exposes under the config, along with the problems found in the spec
+// Unless the config is strict, a spec that doesn't validate still exposes the operations that can be converted
+func loadSpec(cfg *config.Config, spec config.SpecConfig, transport http.RoundTripper, logger *log.Logger) (*registry.Loaded, error) {
+	parseOptions := []parser.ParseOption{
+		parser.WithSpecTransport(transport),
+		parser.WithSpecHeaders(spec.SpecHeaders),
+		parser.WithSpecCache(cfg.SpecCacheDir),
+		parser.WithToolPrefix(spec.Prefix),
+		parser.WithServerVariables(spec.ServerVariables),
+		parser.WithFilter(spec.Filter()),
+	}
+	if spec.Server != "" {
+		parseOptions = append(parseOptions, parser.WithServer(spec.Server))
+	}
+	if spec.BaseURL != "" {
+		parseOptions = append(parseOptions, parser.WithBaseURL(spec.BaseURL))
+	}
+	if spec.Overlay != "" {
+		overlay, err := parser.LoadOverlay(spec.Overlay)
+		if err != nil {
+			return nil, err
+		}
+		parseOptions = append(parseOptions, parser.WithOverlay(overlay))
+	}
+
+	if !cfg.Strict {
+		parseOptions = append(parseOptions, parser.WithLenient())
+	}
+
+	var diagnostics parser.Diagnostics
+	var sources []string
+	parseOptions = append(parseOptions, parser.WithDiagnostics(&diagnostics), parser.WithSources(&sources))
+
+	// Parse the spec
+	tools, err := parser.ParseSpecRouter(spec.Path, parseOptions...)
+	for _, diagnostic := range diagnostics {
+		logger.Printf("%s: %s", spec.Path, diagnostic)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if diagnostics.HasErrors() {
+		logger.Printf("%s: the spec doesn't validate, the operations that could be converted are exposed anyway, pass --strict to refuse it", spec.Path)
+	}
+
+	// the config has the last word on which operations are unsafe
+	var allowed []parser.Tool
+	configured := make(map[string]bool)
+	for _, tool := range tools {
+		operation, ok := spec.Operations[tool.Operation.ID]
+		configured[tool.Operation.ID] = ok
+		if ok && operation.Confirm != nil {
+			tool.Operation.Confirm = operation.Confirm
+		}
+		if cfg.ReadOnly && !tool.Operation.Safe() {
+			logger.Printf("%s: read-only mode, leaving out %s (%s)", spec.Path, tool.Name, tool.Operation.Method)
+			continue
+		}
+		allowed = append(allowed, tool)
+	}
+	for id := range spec.Operations {
+		if !configured[id] {
+			logger.Printf("%s: operations.%s doesn't match any tool of the spec", spec.Path, id)
+		}
+	}
+
+	return &registry.Loaded{Tools: allowed, Diagnostics: diagnostics, Sources: sources}, nil
+}
+
+// Prints the tools every spec exposes
+func listTools(w io.Writer, cfg *config.Config, transport http.RoundTripper) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TOOL\tMETHOD\tURL\tSAFE")
+	total := 0
+	for _, spec := range cfg.Specs {
+		loaded, err := loadSpec(cfg, spec, transport, log.Default())
+		if err != nil {
+			return fmt.Errorf("%s: %w", spec.Path, err)
+		}
+		for _, tool := range loaded.Tools {
+			safe := "yes"
+			if !tool.Operation.Safe() {
+				safe = "no"
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", tool.Name, tool.Operation.Method, tool.Operation.URL, safe)
+		}
+		total += len(loaded.Tools)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d tools\n", total)
+	return err
+}
+
+// Registers the tools of a spec with the server, and its diagnostics with the report
+func registerSpec(s *server.MCPServer, cfg *config.Config, spec config.SpecConfig, transport http.RoundTripper, gate *handlers.ConfirmationGate, report *handlers.DiagnosticsReport, sharedOptions []handlers.HandlerOption) (*registry.Spec, error) {
+	// the options are shared by the handlers of the spec, so the authenticator and rate limiter outlive reloads
+	handlerOptions := append([]handlers.HandlerOption{
+		handlers.WithAuthenticator(auth.NewAuthenticator(spec.Auth, auth.WithTransport(transport))),
+		handlers.WithUploadDirs(cfg.UploadDirs...),
+	}, sharedOptions...)
+	if spec.RateLimit != nil {
+		handlerOptions = append(handlerOptions, handlers.WithRateLimiter(handlers.NewRateLimiter(spec.RateLimit.RequestsPerSecond, spec.RateLimit.Burst)))
+	}
+
+	load := func(logger *log.Logger) (*registry.Loaded, error) {
+		return loadSpec(cfg, spec, transport, logger)
+	}
+	build := func(tool parser.Tool) server.ServerTool {
+		return serverTool(cfg, spec, gate, handlerOptions, tool)
+	}
+	return registry.Register(s, report, spec, load, build)
+}
+
+// Builds the handler of a tool, with the timeout and retries of its operation
+func serverTool(cfg *config.Config, spec config.SpecConfig, gate *handlers.ConfirmationGate, specOptions []handlers.HandlerOption, tool parser.Tool) server.ServerTool {
+	timeout := cfg.Timeout
+	if spec.Timeout > 0 {
+		timeout = spec.Timeout
+	}
+	retry := cfg.Retry
+	if spec.Retry != nil {
+		retry = spec.Retry
+	}
+	if operation, ok := spec.Operations[tool.Operation.ID]; ok {
+		if operation.Timeout > 0 {
+			timeout = operation.Timeout
+		}
+		if operation.Retry != nil {
+			retry = operation.Retry
+		}
+	}
+
+	options := append([]handlers.HandlerOption{handlers.WithTimeout(timeout)}, specOptions...)
+	if retry != nil {
+		options = append(options, handlers.WithRetryPolicy(retryPolicy(retry)))
+	}
+	handler := handlers.CreateOpenAPIMCPToolHandler(tool, options...)
+	if gate != nil {
+		gated, gatedHandler := gate.Gate(tool, handler)
+		return server.ServerTool{Tool: gated, Handler: gatedHandler}
+	}
+	return server.ServerTool{Tool: tool.Tool, Handler: handler}
+}
+
+func retryPolicy(retry *config.RetryConfig) handlers.RetryPolicy {
+	return handlers.RetryPolicy{
+		MaxRetries:     retry.MaxRetries,
+		InitialBackoff: retry.InitialBackoff,
+		MaxBackoff:     retry.MaxBackoff,
+		MaxRetryAfter:  retry.MaxRetryAfter,
+	}
+}
+
+// configFlags are the flags that add to a config file, every other flag describes the specs given as arguments
+var configFlags = map[string]bool{
+	"config":         true,
+	"dry-run":        true,
+	"preview-tool":   true,
+	"confirm-unsafe": true,
+	"read-only":      true,
+	"strict":         true,
+	"watch":          true,
+	"list":           true,
+}
+
+// Returns the flags that were set but only apply to specs given as arguments
+func specFlags() []string {
+	var names []string
+	flag.Visit(func(f *flag.Flag) {
+		if !configFlags[f.Name] {
+			names = append(names, "--"+f.Name)
+		}
+	})
+	return names
+}
+
+// Splits a prefix=path argument, anything that doesn't start with a valid prefix is a plain path
+func splitSpecArg(arg string) (string, string) {
+	prefix, path, ok := strings.Cut(arg, "=")
+	if !ok || !specPrefixPattern.MatchString(prefix) {
+		return "", arg
+	}
+	return prefix, path
+}
+
+var specPrefixPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// keyValueFlag collects repeated name=value flags into a map
+type keyValueFlag map[string]string
+
+func (f keyValueFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for key, value := range f {
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f keyValueFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected name=value, got %q", value)
+	}
+	f[key] = val
+	return nil
+}
+
+// stringListFlag collects repeated flags into a list
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
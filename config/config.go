@@ -0,0 +1,396 @@
+// Package config loads the configuration file of the axon binary.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/evisdrenova/axon-server/handlers/auth"
+	"github.com/evisdrenova/axon-server/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported transports
+const (
+	TransportStdio = "stdio"
+	TransportSSE   = "sse"
+)
+
+// Defaults applied to anything the config file leaves out
+const (
+	DefaultServerName    = "axon-server"
+	DefaultServerVersion = "0.0.1"
+	DefaultSSEAddress    = ":8080"
+	// DefaultWatchInterval is how often spec files are checked for changes
+	DefaultWatchInterval = 2 * time.Second
+	// DefaultRemoteWatchInterval is how often specs loaded from a URL are fetched again
+	DefaultRemoteWatchInterval = time.Minute
+)
+
+var prefixPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+var validMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true, "HEAD": true, "OPTIONS": true, "TRACE": true, "CONNECT": true,
+}
+
+// Config is the root of the configuration file
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Transport TransportConfig `yaml:"transport"`
+	// Timeout applies to every upstream request unless a spec sets its own
+	Timeout time.Duration `yaml:"timeout"`
+	// Retry applies to every upstream request unless a spec sets its own
+	Retry *RetryConfig `yaml:"retry"`
+	// HTTP sets up the transport shared by every upstream request
+	HTTP HTTPConfig `yaml:"http"`
+	// SpecCacheDir keeps copies of remote specs so startup works offline, the user cache directory by default
+	SpecCacheDir string `yaml:"spec_cache_dir"`
+	// UploadDirs are the directories file arguments may be read from by path
+	UploadDirs []string `yaml:"upload_dirs"`
+	// MaxResponseSize caps how many bytes of a response go into a tool result, -1 means no limit
+	MaxResponseSize int `yaml:"max_response_size"`
+	// StashResponses keeps responses that don't fit in a tool result as MCP resources
+	StashResponses bool `yaml:"stash_responses"`
+	// ValidateResponses checks every response against the response schemas in its spec
+	ValidateResponses bool `yaml:"validate_responses"`
+	// DryRun makes every tool return the request it would send instead of sending it
+	DryRun bool `yaml:"dry_run"`
+	// PreviewTool adds a tool that shows the request any other tool would send, without sending it
+	PreviewTool bool `yaml:"preview_tool"`
+	// ConfirmUnsafe makes unsafe operations wait for the user to confirm them, see parser.Operation.Safe
+	ConfirmUnsafe bool `yaml:"confirm_unsafe"`
+	// ReadOnly leaves unsafe operations out altogether
+	ReadOnly bool `yaml:"read_only"`
+	// Strict refuses specs that don't validate, by default the operations that can be converted are exposed anyway
+	Strict bool `yaml:"strict"`
+	// Watch reloads specs while the server runs when they change
+	Watch WatchConfig  `yaml:"watch"`
+	Specs []SpecConfig `yaml:"specs"`
+}
+
+// ServerConfig sets how the MCP server identifies itself to clients
+type ServerConfig struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+}
+
+// TransportConfig selects how clients talk to the MCP server
+type TransportConfig struct {
+	// Type is either stdio or sse
+	Type string `yaml:"type"`
+	// Address the SSE server listens on, e.g. :8080
+	Address string `yaml:"address"`
+	// BaseURL clients use to reach the SSE server, defaults to http://localhost<address>
+	BaseURL string `yaml:"base_url"`
+}
+
+// HTTPConfig sets how upstream APIs are reached
+type HTTPConfig struct {
+	// Proxy is the HTTP(S) proxy to use, HTTP_PROXY and HTTPS_PROXY are used when it is empty
+	Proxy string `yaml:"proxy"`
+	// CAFile is a PEM bundle of certificate authorities to trust on top of the system ones
+	CAFile string `yaml:"ca_file"`
+	// ClientCert and ClientKey are PEM files presented for mutual TLS
+	ClientCert string `yaml:"client_cert"`
+	ClientKey  string `yaml:"client_key"`
+	// InsecureSkipVerify accepts any server certificate, never use it outside of local development
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+	// Headers are sent with every API request, e.g. User-Agent, but not when fetching specs or OAuth2 tokens
+	Headers map[string]string `yaml:"headers"`
+}
+
+// WatchConfig sets how specs are watched for changes
+type WatchConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval between checks of spec files, the spec and every file it references
+	Interval time.Duration `yaml:"interval"`
+	// RemoteInterval between fetches of specs loaded from a URL, which are revalidated with their ETag
+	RemoteInterval time.Duration `yaml:"remote_interval"`
+}
+
+// SpecConfig describes a single API spec to load
+type SpecConfig struct {
+	// Path is a file path, URL or a directory of specs
+	Path string `yaml:"path"`
+	// Prefix namespaces the tools of the spec, e.g. petstore turns getPetById into petstore_getPetById
+	Prefix string `yaml:"prefix"`
+	// BaseURL replaces the servers declared in the spec
+	BaseURL string `yaml:"base_url"`
+	// Server is the index or description of the server to use
+	Server          string            `yaml:"server"`
+	ServerVariables map[string]string `yaml:"server_variables"`
+	// Auth holds credentials keyed by security scheme name
+	Auth map[string]auth.Credentials `yaml:"auth"`
+	// Include and Exclude take operationIds, an operation is kept when it matches every include filter that
+	// is set and none of the exclude filters
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+	// IncludePattern and ExcludePattern are regular expressions matched against operationIds
+	IncludePattern string   `yaml:"include_pattern"`
+	ExcludePattern string   `yaml:"exclude_pattern"`
+	IncludeTags    []string `yaml:"include_tags"`
+	ExcludeTags    []string `yaml:"exclude_tags"`
+	// IncludePaths and ExcludePaths are globs, * matches within a path segment and ** across segments
+	IncludePaths      []string      `yaml:"include_paths"`
+	ExcludePaths      []string      `yaml:"exclude_paths"`
+	IncludeMethods    []string      `yaml:"include_methods"`
+	ExcludeMethods    []string      `yaml:"exclude_methods"`
+	ExcludeDeprecated bool          `yaml:"exclude_deprecated"`
+	Timeout           time.Duration `yaml:"timeout"`
+	Retry             *RetryConfig  `yaml:"retry"`
+	// RateLimit is shared by every tool of the spec
+	RateLimit *RateLimitConfig `yaml:"rate_limit"`
+	// Operations override the settings of the spec for single operations, keyed by operationId
+	Operations map[string]OperationConfig `yaml:"operations"`
+	// Overlay is a file that renames and redescribes operations without editing the spec
+	Overlay string `yaml:"overlay"`
+	// SpecHeaders are sent when fetching a remote spec, e.g. to authenticate with a private spec endpoint
+	SpecHeaders map[string]string `yaml:"spec_headers"`
+}
+
+// OperationConfig overrides the settings of a spec for one operation
+type OperationConfig struct {
+	Timeout time.Duration `yaml:"timeout"`
+	Retry   *RetryConfig  `yaml:"retry"`
+	// Confirm overrides whether the operation is unsafe, like the x-axon-confirm extension
+	Confirm *bool `yaml:"confirm"`
+}
+
+// RetryConfig sets when failed upstream requests are sent again
+type RetryConfig struct {
+	// MaxRetries of zero disables retries
+	MaxRetries     int           `yaml:"max_retries"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	// MaxRetryAfter is the longest Retry-After that is waited for
+	MaxRetryAfter time.Duration `yaml:"max_retry_after"`
+}
+
+// RateLimitConfig caps how many requests are sent to an API
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	// Burst is how many requests may be sent at once, defaults to 1
+	Burst int `yaml:"burst"`
+}
+
+// Load reads, interpolates and validates a YAML or JSON config file
+func Load(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	config, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// Parse interpolates environment variables into the config, decodes it and validates the result
+func Parse(content []byte) (*Config, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	if err := interpolateEnv(&document); err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if document.Kind != 0 {
+		// the node is encoded again so unknown fields are still caught, a yaml.Node can't decode strictly
+		interpolated, err := yaml.Marshal(&document)
+		if err != nil {
+			return nil, err
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(interpolated))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	}
+
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// ApplyDefaults fills in everything the config leaves out
+func (c *Config) ApplyDefaults() {
+	if c.Server.Name == "" {
+		c.Server.Name = DefaultServerName
+	}
+	if c.Server.Version == "" {
+		c.Server.Version = DefaultServerVersion
+	}
+	if c.Transport.Type == "" {
+		c.Transport.Type = TransportStdio
+	}
+	if c.Transport.Type == TransportSSE {
+		if c.Transport.Address == "" {
+			c.Transport.Address = DefaultSSEAddress
+		}
+		if c.Transport.BaseURL == "" {
+			c.Transport.BaseURL = "http://localhost" + c.Transport.Address
+		}
+	}
+	if c.Watch.Interval == 0 {
+		c.Watch.Interval = DefaultWatchInterval
+	}
+	if c.Watch.RemoteInterval == 0 {
+		c.Watch.RemoteInterval = DefaultRemoteWatchInterval
+	}
+}
+
+// Validate checks the config for mistakes and reports all of them at once
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Transport.Type {
+	case TransportStdio, TransportSSE:
+	default:
+		errs = append(errs, fmt.Errorf("transport.type: must be %s or %s, got %q", TransportStdio, TransportSSE, c.Transport.Type))
+	}
+	if c.Transport.Type == TransportSSE && c.Transport.BaseURL != "" && !isAbsoluteURL(c.Transport.BaseURL) {
+		errs = append(errs, fmt.Errorf("transport.base_url: must be an absolute URL, got %q", c.Transport.BaseURL))
+	}
+
+	if c.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout: must not be negative"))
+	}
+	errs = append(errs, c.Retry.validate("retry")...)
+	if c.HTTP.Proxy != "" && !isAbsoluteURL(c.HTTP.Proxy) {
+		errs = append(errs, fmt.Errorf("http.proxy: must be an absolute URL, got %q", c.HTTP.Proxy))
+	}
+	if (c.HTTP.ClientCert == "") != (c.HTTP.ClientKey == "") {
+		errs = append(errs, fmt.Errorf("http: client_cert and client_key must be set together"))
+	}
+	if c.Watch.Interval < 0 || c.Watch.RemoteInterval < 0 {
+		errs = append(errs, fmt.Errorf("watch: intervals must not be negative"))
+	}
+
+	if len(c.Specs) == 0 {
+		errs = append(errs, fmt.Errorf("specs: at least one spec is required"))
+	}
+	for i, spec := range c.Specs {
+		field := fmt.Sprintf("specs[%d]", i)
+
+		if spec.Path == "" {
+			errs = append(errs, fmt.Errorf("%s.path: is required", field))
+		}
+		if spec.BaseURL != "" && !isAbsoluteURL(spec.BaseURL) {
+			errs = append(errs, fmt.Errorf("%s.base_url: must be an absolute URL, got %q", field, spec.BaseURL))
+		}
+		if spec.BaseURL != "" && spec.Server != "" {
+			errs = append(errs, fmt.Errorf("%s: base_url and server can't be used together", field))
+		}
+		if spec.Prefix != "" && !prefixPattern.MatchString(spec.Prefix) {
+			errs = append(errs, fmt.Errorf("%s.prefix: may only contain letters, digits, _ and -, got %q", field, spec.Prefix))
+		}
+		if spec.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("%s.timeout: must not be negative", field))
+		}
+		errs = append(errs, spec.Retry.validate(field+".retry")...)
+		errs = append(errs, validatePattern(field+".include_pattern", spec.IncludePattern)...)
+		errs = append(errs, validatePattern(field+".exclude_pattern", spec.ExcludePattern)...)
+		errs = append(errs, validateMethods(field+".include_methods", spec.IncludeMethods)...)
+		errs = append(errs, validateMethods(field+".exclude_methods", spec.ExcludeMethods)...)
+		if spec.RateLimit != nil {
+			if spec.RateLimit.RequestsPerSecond <= 0 {
+				errs = append(errs, fmt.Errorf("%s.rate_limit.requests_per_second: must be positive", field))
+			}
+			if spec.RateLimit.Burst < 0 {
+				errs = append(errs, fmt.Errorf("%s.rate_limit.burst: must not be negative", field))
+			}
+		}
+		for _, id := range sortedOperationIDs(spec.Operations) {
+			operation := spec.Operations[id]
+			if operation.Timeout < 0 {
+				errs = append(errs, fmt.Errorf("%s.operations.%s.timeout: must not be negative", field, id))
+			}
+			errs = append(errs, operation.Retry.validate(fmt.Sprintf("%s.operations.%s.retry", field, id))...)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *RetryConfig) validate(field string) []error {
+	if r == nil {
+		return nil
+	}
+	var errs []error
+	if r.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_retries: must not be negative", field))
+	}
+	if r.InitialBackoff < 0 || r.MaxBackoff < 0 || r.MaxRetryAfter < 0 {
+		errs = append(errs, fmt.Errorf("%s: durations must not be negative", field))
+	}
+	return errs
+}
+
+func validatePattern(field string, pattern string) []error {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return []error{fmt.Errorf("%s: %w", field, err)}
+	}
+	return nil
+}
+
+func validateMethods(field string, methods []string) []error {
+	var errs []error
+	for _, method := range methods {
+		if !validMethods[strings.ToUpper(method)] {
+			errs = append(errs, fmt.Errorf("%s: unknown HTTP method %q", field, method))
+		}
+	}
+	return errs
+}
+
+func sortedOperationIDs(operations map[string]OperationConfig) []string {
+	ids := make([]string, 0, len(operations))
+	for id := range operations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Filter builds the parser filter of the spec, the patterns have been checked by Validate
+func (s SpecConfig) Filter() parser.Filter {
+	filter := parser.Filter{
+		IncludeOperations: s.Include,
+		ExcludeOperations: s.Exclude,
+		IncludeTags:       s.IncludeTags,
+		ExcludeTags:       s.ExcludeTags,
+		IncludePaths:      s.IncludePaths,
+		ExcludePaths:      s.ExcludePaths,
+		IncludeMethods:    s.IncludeMethods,
+		ExcludeMethods:    s.ExcludeMethods,
+		ExcludeDeprecated: s.ExcludeDeprecated,
+	}
+	if s.IncludePattern != "" {
+		filter.IncludeOperationPattern = regexp.MustCompile(s.IncludePattern)
+	}
+	if s.ExcludePattern != "" {
+		filter.ExcludeOperationPattern = regexp.MustCompile(s.ExcludePattern)
+	}
+	return filter
+}
+
+func isAbsoluteURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && parsed.IsAbs() && parsed.Host != ""
+}
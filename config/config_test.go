@@ -0,0 +1,208 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Setenv("PETSTORE_KEY", "abc123")
+
+	cfg, err := Parse([]byte(`
+server:
+  name: my-apis
+transport:
+  type: sse
+  address: ":9000"
+timeout: 20s
+watch:
+  enabled: true
+  interval: 500ms
+http:
+  proxy: http://proxy.internal:3128
+  ca_file: /etc/ssl/corp.pem
+  headers:
+    User-Agent: axon-tests
+specs:
+  - path: ./petstore.json
+    prefix: petstore
+    server: staging
+    server_variables:
+      region: eu
+    auth:
+      api_key:
+        api_key: ${PETSTORE_KEY}
+    exclude: [deletePet]
+    timeout: 5s
+    retry:
+      max_retries: 3
+      initial_backoff: 200ms
+    rate_limit:
+      requests_per_second: 2.5
+      burst: 5
+    operations:
+      uploadFile:
+        timeout: 2m
+        retry: {max_retries: 0}
+        confirm: true
+  - path: ${WEATHER_SPEC:-./weather.json}
+    base_url: https://api.weather.gov
+    include_tags: [alerts]
+    exclude_paths: ["/alerts/**/count"]
+    include_pattern: ^alerts_
+    exclude_methods: [delete]
+    exclude_deprecated: true
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-apis", cfg.Server.Name)
+	assert.Equal(t, DefaultServerVersion, cfg.Server.Version)
+	assert.Equal(t, TransportSSE, cfg.Transport.Type)
+	assert.Equal(t, "http://localhost:9000", cfg.Transport.BaseURL)
+	assert.Equal(t, 20*time.Second, cfg.Timeout)
+	assert.Equal(t, WatchConfig{Enabled: true, Interval: 500 * time.Millisecond, RemoteInterval: DefaultRemoteWatchInterval}, cfg.Watch)
+	assert.Equal(t, HTTPConfig{
+		Proxy:   "http://proxy.internal:3128",
+		CAFile:  "/etc/ssl/corp.pem",
+		Headers: map[string]string{"User-Agent": "axon-tests"},
+	}, cfg.HTTP)
+
+	require.Len(t, cfg.Specs, 2)
+	assert.Equal(t, "petstore", cfg.Specs[0].Prefix)
+	assert.Equal(t, "staging", cfg.Specs[0].Server)
+	assert.Equal(t, map[string]string{"region": "eu"}, cfg.Specs[0].ServerVariables)
+	assert.Equal(t, "abc123", cfg.Specs[0].Auth["api_key"].APIKey)
+	assert.Equal(t, []string{"deletePet"}, cfg.Specs[0].Exclude)
+	assert.Equal(t, 5*time.Second, cfg.Specs[0].Timeout)
+	assert.Equal(t, &RetryConfig{MaxRetries: 3, InitialBackoff: 200 * time.Millisecond}, cfg.Specs[0].Retry)
+	assert.Equal(t, &RateLimitConfig{RequestsPerSecond: 2.5, Burst: 5}, cfg.Specs[0].RateLimit)
+	confirm := true
+	assert.Equal(t, OperationConfig{Timeout: 2 * time.Minute, Retry: &RetryConfig{}, Confirm: &confirm}, cfg.Specs[0].Operations["uploadFile"])
+	assert.Equal(t, "./weather.json", cfg.Specs[1].Path)
+
+	filter := cfg.Specs[1].Filter()
+	assert.Equal(t, []string{"alerts"}, filter.IncludeTags)
+	assert.Equal(t, []string{"/alerts/**/count"}, filter.ExcludePaths)
+	assert.Equal(t, "^alerts_", filter.IncludeOperationPattern.String())
+	assert.Nil(t, filter.ExcludeOperationPattern)
+	assert.Equal(t, []string{"delete"}, filter.ExcludeMethods)
+	assert.True(t, filter.ExcludeDeprecated)
+}
+
+func TestParse_JSON(t *testing.T) {
+	cfg, err := Parse([]byte(`{"dry_run": true, "specs": [{"path": "spec.json", "timeout": "1m"}]}`))
+	require.NoError(t, err)
+	assert.True(t, cfg.DryRun)
+	assert.Equal(t, TransportStdio, cfg.Transport.Type)
+	assert.Equal(t, time.Minute, cfg.Specs[0].Timeout)
+}
+
+func TestParse_Environment(t *testing.T) {
+	t.Setenv("AXON_TEST_PASSWORD", "abc #def")
+	t.Setenv("AXON_TEST_HEADER", "key: value")
+	t.Setenv("AXON_TEST_SIZE", "5000")
+
+	cfg, err := Parse([]byte(`
+# set ${AXON_TEST_UNSET_VARIABLE} to change nothing, comments aren't interpolated
+max_response_size: ${AXON_TEST_SIZE}
+http:
+  headers:
+    X-Note: ${AXON_TEST_HEADER}
+specs:
+  - path: ./petstore.json
+    auth:
+      basic:
+        username: me
+        password: ${AXON_TEST_PASSWORD}
+`))
+	require.NoError(t, err)
+
+	// values are taken as they are, YAML in them is not parsed
+	assert.Equal(t, "abc #def", cfg.Specs[0].Auth["basic"].Password)
+	assert.Equal(t, "key: value", cfg.HTTP.Headers["X-Note"])
+	assert.Equal(t, 5000, cfg.MaxResponseSize)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "no specs",
+			content:  `server: {name: x}`,
+			expected: []string{"specs: at least one spec is required"},
+		},
+		{
+			name: "every problem is reported",
+			content: `
+transport: {type: websocket}
+http: {proxy: "proxy:3128", client_cert: cert.pem}
+watch: {interval: -1s}
+specs:
+  - base_url: not-a-url
+    server: staging
+    prefix: pet store
+    retry: {max_retries: -1}
+    rate_limit: {burst: 2}
+    operations:
+      getPet: {timeout: -1s}
+    include_pattern: "(unclosed"
+    exclude_methods: [FETCH]
+`,
+			expected: []string{
+				`transport.type: must be stdio or sse, got "websocket"`,
+				`http.proxy: must be an absolute URL, got "proxy:3128"`,
+				"http: client_cert and client_key must be set together",
+				"watch: intervals must not be negative",
+				"specs[0].path: is required",
+				`specs[0].base_url: must be an absolute URL, got "not-a-url"`,
+				"specs[0]: base_url and server can't be used together",
+				`specs[0].prefix: may only contain letters, digits, _ and -, got "pet store"`,
+				"specs[0].retry.max_retries: must not be negative",
+				"specs[0].rate_limit.requests_per_second: must be positive",
+				"specs[0].operations.getPet.timeout: must not be negative",
+				"specs[0].include_pattern: error parsing regexp",
+				`specs[0].exclude_methods: unknown HTTP method "FETCH"`,
+			},
+		},
+		{
+			name:     "unknown fields",
+			content:  "specs:\n  - path: a.json\n    tiemout: 5s\n",
+			expected: []string{"field tiemout not found"},
+		},
+		{
+			name:     "missing environment variables",
+			content:  "specs:\n  - path: ${AXON_TEST_UNSET_VARIABLE}\n",
+			expected: []string{"AXON_TEST_UNSET_VARIABLE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			require.Error(t, err)
+			for _, expected := range tt.expected {
+				assert.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "axon.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("specs:\n  - path: spec.json\n"), 0644))
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "spec.json", cfg.Specs[0].Path)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read config file")
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Matches $$, ${NAME} and ${NAME:-default}
+var envPattern = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Replaces ${NAME} references in the scalars of a YAML document with the value of the environment variable
+// ${NAME:-default} falls back to the default when the variable is unset or empty and $$ escapes a literal $
+// Only values are interpolated, so comments are left alone and a value can't change the structure of the document
+func interpolateEnv(node *yaml.Node) error {
+	var missing []string
+	interpolateNode(node, &missing)
+
+	if len(missing) > 0 {
+		return fmt.Errorf("environment variables referenced by the config are not set: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func interpolateNode(node *yaml.Node, missing *[]string) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		value := interpolateString(node.Value, missing)
+		if value == node.Value {
+			return
+		}
+		node.Value = value
+		// an unquoted ${PORT} becomes whatever its value reads as, e.g. a number, quoted ones stay strings
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+	case yaml.AliasNode:
+		// the anchored node is interpolated where it is defined
+	default:
+		for _, child := range node.Content {
+			interpolateNode(child, missing)
+		}
+	}
+}
+
+func interpolateString(value string, missing *[]string) string {
+	return envPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+
+		groups := envPattern.FindStringSubmatch(match)
+		name, hasDefault, fallback := groups[1], groups[2] != "", groups[3]
+
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+		if hasDefault {
+			return fallback
+		}
+		*missing = append(*missing, name)
+		return match
+	})
+}
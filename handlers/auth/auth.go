@@ -0,0 +1,329 @@
+// Package auth attaches credentials to outgoing API requests based on the security schemes declared in the spec.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evisdrenova/axon-server/parser"
+)
+
+// Tokens are refreshed this long before they expire so a request never goes out with a stale one
+const tokenExpiryLeeway = 30 * time.Second
+
+// Placeholder stands in for every credential Preview attaches
+const Placeholder = "REDACTED"
+
+// Authenticator applies credentials to requests and caches the OAuth2 tokens it fetches
+type Authenticator struct {
+	credentials map[string]Credentials
+	client      *http.Client
+
+	mu sync.Mutex
+	// tokens are keyed by tokenKey, the specs of a directory share an authenticator and may reuse scheme names
+	tokens map[string]*cachedToken
+}
+
+type cachedToken struct {
+	accessToken string
+	expiresAt   time.Time
+}
+
+// Option configures an Authenticator
+type Option func(*Authenticator)
+
+// WithTransport fetches OAuth2 tokens through the given transport instead of the default one
+func WithTransport(transport http.RoundTripper) Option {
+	return func(a *Authenticator) {
+		a.client.Transport = transport
+	}
+}
+
+// NewAuthenticator creates an authenticator from credentials keyed by security scheme name
+// Schemes without an entry fall back to the AXON_AUTH_<SCHEME>_* environment variables
+func NewAuthenticator(credentials map[string]Credentials, opts ...Option) *Authenticator {
+	if credentials == nil {
+		credentials = make(map[string]Credentials)
+	}
+
+	authenticator := &Authenticator{
+		credentials: credentials,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		tokens:      make(map[string]*cachedToken),
+	}
+	for _, opt := range opts {
+		opt(authenticator)
+	}
+	return authenticator
+}
+
+// Apply attaches the credentials of the first security requirement that can be satisfied to the request
+// Returns an error if credentials are missing for every requirement
+func (a *Authenticator) Apply(
+	ctx context.Context,
+	req *http.Request,
+	requirements []parser.SecurityRequirement,
+) error {
+	if len(requirements) == 0 {
+		return nil
+	}
+
+	var missing, undefined []string
+	for _, requirement := range requirements {
+		satisfied := true
+		for _, scheme := range requirement {
+			switch {
+			case scheme.Type == "":
+				// the spec names a scheme it doesn't define, no credentials can satisfy it
+				satisfied = false
+				undefined = append(undefined, scheme.Name)
+			case !a.canSatisfy(scheme):
+				satisfied = false
+				missing = append(missing, scheme.Name)
+			}
+		}
+		if !satisfied {
+			continue
+		}
+
+		for _, scheme := range requirement {
+			if err := a.applyScheme(ctx, req, scheme); err != nil {
+				return fmt.Errorf("failed to apply security scheme %s: %w", scheme.Name, err)
+			}
+		}
+		return nil
+	}
+
+	if len(missing) == 0 {
+		return fmt.Errorf("security scheme %s is not defined by the spec, the request can't be authorized", strings.Join(undefined, " or "))
+	}
+	return fmt.Errorf(
+		"no credentials configured for security scheme %s, add them to the credentials file or set the %s* environment variables",
+		strings.Join(missing, " or "),
+		EnvVarPrefix(missing[0]),
+	)
+}
+
+// Preview attaches placeholders where Apply would put credentials, for requests that are shown but not sent
+// The placeholders follow the first requirement that can be satisfied, or the first requirement when none can
+// Nothing is fetched and missing credentials are no error, so a dry run has no side effects
+func (a *Authenticator) Preview(req *http.Request, requirements []parser.SecurityRequirement) {
+	if len(requirements) == 0 {
+		return
+	}
+
+	chosen := requirements[0]
+	for _, requirement := range requirements {
+		satisfied := true
+		for _, scheme := range requirement {
+			satisfied = satisfied && a.canSatisfy(scheme)
+		}
+		if satisfied {
+			chosen = requirement
+			break
+		}
+	}
+
+	placeholders := Credentials{APIKey: Placeholder, Username: Placeholder, Password: Placeholder, Token: Placeholder}
+	for _, scheme := range chosen {
+		// an unsupported scheme fails when the request is really sent, there is nothing to show for it here
+		_ = attachCredentials(req, scheme, placeholders, Placeholder)
+	}
+}
+
+// Invalidate drops the cached OAuth2 tokens used by the requirements so the next request fetches new ones
+// Returns whether there was anything to drop
+func (a *Authenticator) Invalidate(requirements []parser.SecurityRequirement) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	dropped := false
+	for _, requirement := range requirements {
+		for _, scheme := range requirement {
+			credentials, _ := a.lookup(scheme.Name)
+			key := tokenKey(scheme, credentials)
+			if _, ok := a.tokens[key]; ok {
+				delete(a.tokens, key)
+				dropped = true
+			}
+		}
+	}
+	return dropped
+}
+
+func (a *Authenticator) lookup(schemeName string) (Credentials, bool) {
+	if credentials, ok := a.credentials[schemeName]; ok {
+		return credentials, true
+	}
+	return CredentialsFromEnv(schemeName)
+}
+
+func (a *Authenticator) canSatisfy(scheme parser.SecurityScheme) bool {
+	credentials, ok := a.lookup(scheme.Name)
+	if !ok {
+		return false
+	}
+
+	switch scheme.Type {
+	case parser.SecurityTypeAPIKey:
+		return credentials.APIKey != ""
+	case parser.SecurityTypeHTTP:
+		if scheme.Scheme == "basic" {
+			return credentials.Username != ""
+		}
+		return credentials.Token != ""
+	case parser.SecurityTypeOAuth2:
+		if credentials.Token != "" {
+			return true
+		}
+		return credentials.ClientID != "" && credentials.ClientSecret != "" && tokenURL(scheme, credentials) != ""
+	case parser.SecurityTypeOpenIDConnect:
+		return credentials.Token != ""
+	default:
+		return false
+	}
+}
+
+func (a *Authenticator) applyScheme(ctx context.Context, req *http.Request, scheme parser.SecurityScheme) error {
+	credentials, _ := a.lookup(scheme.Name)
+
+	token := credentials.Token
+	if token == "" && (scheme.Type == parser.SecurityTypeOAuth2 || scheme.Type == parser.SecurityTypeOpenIDConnect) {
+		var err error
+		token, err = a.clientCredentialsToken(ctx, scheme, credentials)
+		if err != nil {
+			return err
+		}
+	}
+	return attachCredentials(req, scheme, credentials, token)
+}
+
+// Puts the credentials of a scheme on the request, token is the bearer token of OAuth2 and OpenID Connect schemes
+func attachCredentials(req *http.Request, scheme parser.SecurityScheme, credentials Credentials, token string) error {
+	switch scheme.Type {
+	case parser.SecurityTypeAPIKey:
+		switch scheme.In {
+		case parser.ParameterInHeader:
+			req.Header.Set(scheme.ParamName, credentials.APIKey)
+		case parser.ParameterInQuery:
+			query := req.URL.Query()
+			query.Set(scheme.ParamName, credentials.APIKey)
+			req.URL.RawQuery = query.Encode()
+		case parser.ParameterInCookie:
+			req.AddCookie(&http.Cookie{Name: scheme.ParamName, Value: credentials.APIKey})
+		default:
+			return fmt.Errorf("unsupported API key location %q", scheme.In)
+		}
+
+	case parser.SecurityTypeHTTP:
+		switch scheme.Scheme {
+		case "basic":
+			req.SetBasicAuth(credentials.Username, credentials.Password)
+		case "bearer", "":
+			req.Header.Set("Authorization", "Bearer "+credentials.Token)
+		default:
+			req.Header.Set("Authorization", scheme.Scheme+" "+credentials.Token)
+		}
+
+	case parser.SecurityTypeOAuth2, parser.SecurityTypeOpenIDConnect:
+		req.Header.Set("Authorization", "Bearer "+token)
+
+	default:
+		return fmt.Errorf("unsupported security scheme type %q", scheme.Type)
+	}
+
+	return nil
+}
+
+// Returns a cached access token for the scheme, fetching a new one with the client credentials grant when needed
+func (a *Authenticator) clientCredentialsToken(
+	ctx context.Context,
+	scheme parser.SecurityScheme,
+	credentials Credentials,
+) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := tokenKey(scheme, credentials)
+	if token, ok := a.tokens[key]; ok {
+		if token.expiresAt.IsZero() || time.Now().Add(tokenExpiryLeeway).Before(token.expiresAt) {
+			return token.accessToken, nil
+		}
+	}
+
+	scopes := credentials.Scopes
+	if len(scopes) == 0 {
+		scopes = scheme.Scopes
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL(scheme, credentials), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(credentials.ClientID), url.QueryEscape(credentials.ClientSecret))
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if tokenResponse.AccessToken == "" {
+		return "", fmt.Errorf("token response did not contain an access token")
+	}
+
+	token := &cachedToken{accessToken: tokenResponse.AccessToken}
+	if tokenResponse.ExpiresIn > 0 {
+		token.expiresAt = time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+	}
+	a.tokens[key] = token
+
+	return token.accessToken, nil
+}
+
+// Identifies the token a scheme gets, two specs only share a token when they ask the same endpoint for it
+// as the same client and with the same scopes
+func tokenKey(scheme parser.SecurityScheme, credentials Credentials) string {
+	scopes := credentials.Scopes
+	if len(scopes) == 0 {
+		scopes = scheme.Scopes
+	}
+	return strings.Join([]string{scheme.Name, tokenURL(scheme, credentials), credentials.ClientID, strings.Join(scopes, " ")}, "\n")
+}
+
+// The token URL from the credentials wins over the one declared in the spec
+func tokenURL(scheme parser.SecurityScheme, credentials Credentials) string {
+	if credentials.TokenURL != "" {
+		return credentials.TokenURL
+	}
+	return scheme.TokenURL
+}
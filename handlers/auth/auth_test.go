@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evisdrenova/axon-server/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticator_Apply(t *testing.T) {
+	tests := []struct {
+		name        string
+		scheme      parser.SecurityScheme
+		credentials Credentials
+		validate    func(t *testing.T, req *http.Request)
+	}{
+		{
+			name:        "api key in header",
+			scheme:      parser.SecurityScheme{Name: "key", Type: parser.SecurityTypeAPIKey, In: "header", ParamName: "X-API-Key"},
+			credentials: Credentials{APIKey: "secret"},
+			validate: func(t *testing.T, req *http.Request) {
+				assert.Equal(t, "secret", req.Header.Get("X-API-Key"))
+			},
+		},
+		{
+			name:        "api key in query",
+			scheme:      parser.SecurityScheme{Name: "key", Type: parser.SecurityTypeAPIKey, In: "query", ParamName: "api_key"},
+			credentials: Credentials{APIKey: "secret"},
+			validate: func(t *testing.T, req *http.Request) {
+				assert.Equal(t, "secret", req.URL.Query().Get("api_key"))
+				assert.Equal(t, "1", req.URL.Query().Get("page"))
+			},
+		},
+		{
+			name:        "api key in cookie",
+			scheme:      parser.SecurityScheme{Name: "key", Type: parser.SecurityTypeAPIKey, In: "cookie", ParamName: "session"},
+			credentials: Credentials{APIKey: "secret"},
+			validate: func(t *testing.T, req *http.Request) {
+				cookie, err := req.Cookie("session")
+				require.NoError(t, err)
+				assert.Equal(t, "secret", cookie.Value)
+			},
+		},
+		{
+			name:        "http basic",
+			scheme:      parser.SecurityScheme{Name: "basic", Type: parser.SecurityTypeHTTP, Scheme: "basic"},
+			credentials: Credentials{Username: "user", Password: "pass"},
+			validate: func(t *testing.T, req *http.Request) {
+				username, password, ok := req.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "user", username)
+				assert.Equal(t, "pass", password)
+			},
+		},
+		{
+			name:        "http bearer",
+			scheme:      parser.SecurityScheme{Name: "bearer", Type: parser.SecurityTypeHTTP, Scheme: "bearer"},
+			credentials: Credentials{Token: "token"},
+			validate: func(t *testing.T, req *http.Request) {
+				assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := NewAuthenticator(map[string]Credentials{tt.scheme.Name: tt.credentials})
+			req, err := http.NewRequest(http.MethodGet, "https://api.example.com/pets?page=1", nil)
+			require.NoError(t, err)
+
+			err = authenticator.Apply(context.Background(), req, []parser.SecurityRequirement{{tt.scheme}})
+			require.NoError(t, err)
+			tt.validate(t, req)
+		})
+	}
+}
+
+func TestAuthenticator_PicksSatisfiableRequirement(t *testing.T) {
+	authenticator := NewAuthenticator(map[string]Credentials{"bearer": {Token: "token"}})
+	requirements := []parser.SecurityRequirement{
+		{{Name: "key", Type: parser.SecurityTypeAPIKey, In: "header", ParamName: "X-API-Key"}},
+		{{Name: "bearer", Type: parser.SecurityTypeHTTP, Scheme: "bearer"}},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.example.com/pets", nil)
+	require.NoError(t, err)
+	require.NoError(t, authenticator.Apply(context.Background(), req, requirements))
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	assert.Empty(t, req.Header.Get("X-API-Key"))
+
+	err = NewAuthenticator(nil).Apply(context.Background(), req, requirements[:1])
+	assert.ErrorContains(t, err, "AXON_AUTH_KEY_")
+}
+
+func TestAuthenticator_UndefinedScheme(t *testing.T) {
+	// credentials named like the scheme don't help, the spec doesn't say how to attach them
+	authenticator := NewAuthenticator(map[string]Credentials{"petstore_auth": {Token: "token"}})
+	req, err := http.NewRequest(http.MethodGet, "https://api.example.com/pets", nil)
+	require.NoError(t, err)
+
+	err = authenticator.Apply(context.Background(), req, []parser.SecurityRequirement{{{Name: "petstore_auth"}}})
+	assert.ErrorContains(t, err, "security scheme petstore_auth is not defined by the spec")
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestAuthenticator_CredentialsFromEnv(t *testing.T) {
+	t.Setenv("AXON_AUTH_PETSTORE_AUTH_TOKEN", "from-env")
+	authenticator := NewAuthenticator(nil)
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.example.com/pets", nil)
+	require.NoError(t, err)
+	scheme := parser.SecurityScheme{Name: "petstore-auth", Type: parser.SecurityTypeHTTP, Scheme: "bearer"}
+	require.NoError(t, authenticator.Apply(context.Background(), req, []parser.SecurityRequirement{{scheme}}))
+	assert.Equal(t, "Bearer from-env", req.Header.Get("Authorization"))
+}
+
+func TestAuthenticator_ClientCredentials(t *testing.T) {
+	tokenRequests := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		clientID, clientSecret, _ := r.BasicAuth()
+		assert.Equal(t, "client", clientID)
+		assert.Equal(t, "secret", clientSecret)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "read write", r.PostForm.Get("scope"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "bearer", "expires_in": 3600}`, tokenRequests)
+	}))
+	defer tokenServer.Close()
+
+	scheme := parser.SecurityScheme{
+		Name:     "oauth",
+		Type:     parser.SecurityTypeOAuth2,
+		TokenURL: tokenServer.URL,
+		Scopes:   []string{"read", "write"},
+	}
+	requirements := []parser.SecurityRequirement{{scheme}}
+	authenticator := NewAuthenticator(map[string]Credentials{"oauth": {ClientID: "client", ClientSecret: "secret"}})
+
+	apply := func() string {
+		req, err := http.NewRequest(http.MethodGet, "https://api.example.com/pets", nil)
+		require.NoError(t, err)
+		require.NoError(t, authenticator.Apply(context.Background(), req, requirements))
+		return req.Header.Get("Authorization")
+	}
+
+	assert.Equal(t, "Bearer token-1", apply())
+	assert.Equal(t, "Bearer token-1", apply(), "token should be cached")
+	assert.True(t, authenticator.Invalidate(requirements))
+	assert.Equal(t, "Bearer token-2", apply(), "token should be refetched after invalidation")
+	assert.Equal(t, 2, tokenRequests)
+}
+
+// Directory specs share an authenticator, a scheme name used by two of them must not share a token
+func TestAuthenticator_ClientCredentialsPerTokenURL(t *testing.T) {
+	newTokenServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token": "%s-token", "expires_in": 3600}`, name)
+		}))
+	}
+	pets, store := newTokenServer("pets"), newTokenServer("store")
+	defer pets.Close()
+	defer store.Close()
+
+	petsRequirements := []parser.SecurityRequirement{{{Name: "oauth2", Type: parser.SecurityTypeOAuth2, TokenURL: pets.URL}}}
+	storeRequirements := []parser.SecurityRequirement{{{Name: "oauth2", Type: parser.SecurityTypeOAuth2, TokenURL: store.URL}}}
+	authenticator := NewAuthenticator(map[string]Credentials{"oauth2": {ClientID: "client", ClientSecret: "secret"}})
+
+	apply := func(requirements []parser.SecurityRequirement) string {
+		req, err := http.NewRequest(http.MethodGet, "https://api.example.com/pets", nil)
+		require.NoError(t, err)
+		require.NoError(t, authenticator.Apply(context.Background(), req, requirements))
+		return req.Header.Get("Authorization")
+	}
+
+	assert.Equal(t, "Bearer pets-token", apply(petsRequirements))
+	assert.Equal(t, "Bearer store-token", apply(storeRequirements))
+
+	assert.True(t, authenticator.Invalidate(petsRequirements))
+	assert.False(t, authenticator.Invalidate(petsRequirements))
+	assert.True(t, authenticator.Invalidate(storeRequirements), "invalidating one spec's token keeps the other's")
+}
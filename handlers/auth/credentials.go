@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Prefix of the environment variables credentials are read from, e.g. AXON_AUTH_PETSTORE_AUTH_TOKEN
+const EnvPrefix = "AXON_AUTH"
+
+var invalidEnvChars = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// Credentials holds the secrets for a single security scheme
+// Which fields are needed depends on the scheme type:
+// apiKey uses APIKey, http basic uses Username and Password, http bearer uses Token
+// and oauth2 uses either a static Token or ClientID and ClientSecret for the client credentials flow
+type Credentials struct {
+	APIKey       string   `json:"api_key,omitempty" yaml:"api_key,omitempty"`
+	Username     string   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password     string   `json:"password,omitempty" yaml:"password,omitempty"`
+	Token        string   `json:"token,omitempty" yaml:"token,omitempty"`
+	ClientID     string   `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+	TokenURL     string   `json:"token_url,omitempty" yaml:"token_url,omitempty"`
+	Scopes       []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+}
+
+// LoadCredentialsFile reads a YAML or JSON file that maps security scheme names to their credentials
+func LoadCredentialsFile(path string) (map[string]Credentials, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	credentials := make(map[string]Credentials)
+	if err := yaml.Unmarshal(content, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file %s: %w", path, err)
+	}
+
+	return credentials, nil
+}
+
+// CredentialsFromEnv reads the credentials for a security scheme from the environment
+// The variables are named AXON_AUTH_<SCHEME>_<FIELD>, where the scheme name is upper cased and
+// every run of other characters is replaced by an underscore, e.g. AXON_AUTH_API_KEY_API_KEY for the scheme api_key
+func CredentialsFromEnv(schemeName string) (Credentials, bool) {
+	prefix := EnvVarPrefix(schemeName)
+
+	credentials := Credentials{
+		APIKey:       os.Getenv(prefix + "API_KEY"),
+		Username:     os.Getenv(prefix + "USERNAME"),
+		Password:     os.Getenv(prefix + "PASSWORD"),
+		Token:        os.Getenv(prefix + "TOKEN"),
+		ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+		ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+		TokenURL:     os.Getenv(prefix + "TOKEN_URL"),
+	}
+	if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+		credentials.Scopes = strings.FieldsFunc(scopes, func(r rune) bool { return r == ' ' || r == ',' })
+	}
+
+	found := credentials.APIKey != "" || credentials.Username != "" || credentials.Token != "" || credentials.ClientID != ""
+	return credentials, found
+}
+
+// EnvVarPrefix returns the prefix of the environment variables holding the credentials for a scheme
+func EnvVarPrefix(schemeName string) string {
+	name := strings.Trim(invalidEnvChars.ReplaceAllString(strings.ToUpper(schemeName), "_"), "_")
+	return fmt.Sprintf("%s_%s_", EnvPrefix, name)
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/evisdrenova/axon-server/parser"
+)
+
+// uploadFile is a file argument after its content has been read
+type uploadFile struct {
+	name        string
+	contentType string
+	content     []byte
+}
+
+// Encodes the body argument as the media type of the operation and returns it with the Content-Type to send
+// Operations without body metadata send JSON
+func encodeBody(body *parser.RequestBody, value interface{}, uploadDirs []string) ([]byte, string, error) {
+	if body == nil {
+		body = &parser.RequestBody{MediaType: "application/json", Encoding: parser.BodyEncodingJSON}
+	}
+
+	switch body.Encoding {
+	case parser.BodyEncodingForm:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("body must be an object to be sent as %s", body.MediaType)
+		}
+		form := url.Values{}
+		for _, name := range sortedNames(fields) {
+			if values, ok := fields[name].([]interface{}); ok {
+				for _, item := range values {
+					form.Add(name, formatPrimitive(item))
+				}
+				continue
+			}
+			form.Add(name, formatPrimitive(fields[name]))
+		}
+		return []byte(form.Encode()), body.MediaType, nil
+
+	case parser.BodyEncodingMultipart:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("body must be an object to be sent as %s", body.MediaType)
+		}
+		return encodeMultipart(body, fields, uploadDirs)
+
+	case parser.BodyEncodingText:
+		return []byte(formatPrimitive(value)), body.MediaType, nil
+
+	case parser.BodyEncodingBinary:
+		file, err := readUploadFile(value, "body", uploadDirs)
+		if err != nil {
+			return nil, "", err
+		}
+		contentType := body.MediaType
+		// a wildcard like */* or image/* leaves the actual type up to the file
+		if strings.HasSuffix(contentType, "/*") {
+			contentType = file.contentType
+		}
+		return file.content, contentType, nil
+
+	default:
+		encoded, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return nil, "", err
+		}
+		return encoded, body.MediaType, nil
+	}
+}
+
+// Writes the fields of a multipart body, file fields take one file or an array of them
+func encodeMultipart(body *parser.RequestBody, fields map[string]interface{}, uploadDirs []string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	for _, name := range sortedNames(fields) {
+		value := fields[name]
+
+		if containsName(body.FileFields, name) {
+			files, ok := value.([]interface{})
+			if !ok {
+				files = []interface{}{value}
+			}
+			for _, item := range files {
+				file, err := readUploadFile(item, name, uploadDirs)
+				if err != nil {
+					return nil, "", fmt.Errorf("field %s: %w", name, err)
+				}
+
+				header := make(textproto.MIMEHeader)
+				header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": name, "filename": file.name}))
+				header.Set("Content-Type", file.contentType)
+				part, err := writer.CreatePart(header)
+				if err != nil {
+					return nil, "", err
+				}
+				if _, err := part.Write(file.content); err != nil {
+					return nil, "", err
+				}
+			}
+			continue
+		}
+
+		switch v := value.(type) {
+		case []interface{}:
+			for _, item := range v {
+				if err := writer.WriteField(name, formatPrimitive(item)); err != nil {
+					return nil, "", err
+				}
+			}
+		case map[string]interface{}:
+			// the spec's default content type for object parts is JSON
+			header := make(textproto.MIMEHeader)
+			header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": name}))
+			header.Set("Content-Type", "application/json")
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := part.Write([]byte(formatPrimitive(v))); err != nil {
+				return nil, "", err
+			}
+		default:
+			if err := writer.WriteField(name, formatPrimitive(v)); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// Reads a file argument, which is either a base64 string or an object with base64 content or a path
+func readUploadFile(value interface{}, fieldName string, uploadDirs []string) (uploadFile, error) {
+	file := uploadFile{name: fieldName}
+
+	var content, path string
+	switch v := value.(type) {
+	case string:
+		content = v
+	case map[string]interface{}:
+		content, _ = v["content"].(string)
+		path, _ = v["path"].(string)
+		if name, ok := v["filename"].(string); ok && name != "" {
+			file.name = name
+		}
+		if contentType, ok := v["contentType"].(string); ok {
+			file.contentType = contentType
+		}
+	default:
+		return uploadFile{}, fmt.Errorf("expected a file object with content or path")
+	}
+
+	switch {
+	case content != "" && path != "":
+		return uploadFile{}, fmt.Errorf("a file takes either content or path, not both")
+	case content != "":
+		decoded, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return uploadFile{}, fmt.Errorf("file content is not valid base64: %w", err)
+		}
+		file.content = decoded
+	case path != "":
+		resolved, err := resolveUploadPath(path, uploadDirs)
+		if err != nil {
+			return uploadFile{}, err
+		}
+		file.content, err = os.ReadFile(resolved)
+		if err != nil {
+			return uploadFile{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		if file.name == fieldName {
+			file.name = filepath.Base(resolved)
+		}
+	default:
+		return uploadFile{}, fmt.Errorf("a file needs content or path")
+	}
+
+	if file.contentType == "" {
+		file.contentType = mime.TypeByExtension(filepath.Ext(file.name))
+	}
+	if file.contentType == "" {
+		file.contentType = http.DetectContentType(file.content)
+	}
+
+	return file, nil
+}
+
+// Resolves the path of a file to upload and makes sure it lies inside one of the upload directories
+// Relative paths are looked up in each upload directory in turn
+func resolveUploadPath(path string, uploadDirs []string) (string, error) {
+	if len(uploadDirs) == 0 {
+		return "", fmt.Errorf("uploading files by path is disabled, no upload directory is configured")
+	}
+
+	for _, dir := range uploadDirs {
+		candidate := path
+		if !filepath.IsAbs(candidate) {
+			candidate = filepath.Join(dir, candidate)
+		}
+
+		// symlinks are resolved first so a link can't point outside of the directory
+		resolved, err := filepath.EvalSymlinks(candidate)
+		if err != nil {
+			continue
+		}
+		root, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		resolved, _ = filepath.Abs(resolved)
+		root, _ = filepath.Abs(root)
+
+		rel, err := filepath.Rel(root, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+
+	return "", fmt.Errorf("file %s is not inside an upload directory", path)
+}
+
+// Describes a request body for the log, files are summarized instead of dumped
+func describeBody(body *parser.RequestBody, encoded []byte) string {
+	if body != nil && (body.Encoding == parser.BodyEncodingMultipart || body.Encoding == parser.BodyEncodingBinary) {
+		return fmt.Sprintf("<%d bytes of %s>", len(encoded), body.MediaType)
+	}
+	return string(encoded)
+}
+
+func sortedNames(fields map[string]interface{}) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
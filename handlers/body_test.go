@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evisdrenova/axon-server/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        *parser.RequestBody
+		value       interface{}
+		expected    string
+		contentType string
+	}{
+		{
+			name:        "json by default",
+			value:       map[string]interface{}{"name": "Rex"},
+			expected:    "{\n  \"name\": \"Rex\"\n}",
+			contentType: "application/json",
+		},
+		{
+			name:        "form",
+			body:        &parser.RequestBody{MediaType: "application/x-www-form-urlencoded", Encoding: parser.BodyEncodingForm},
+			value:       map[string]interface{}{"name": "Rex Jr", "tags": []interface{}{"a", "b"}, "age": float64(3)},
+			expected:    "age=3&name=Rex+Jr&tags=a&tags=b",
+			contentType: "application/x-www-form-urlencoded",
+		},
+		{
+			name:        "text",
+			body:        &parser.RequestBody{MediaType: "application/xml", Encoding: parser.BodyEncodingText},
+			value:       "<pet><name>Rex</name></pet>",
+			expected:    "<pet><name>Rex</name></pet>",
+			contentType: "application/xml",
+		},
+		{
+			name:        "binary takes the file's type for wildcards",
+			body:        &parser.RequestBody{MediaType: "image/*", Encoding: parser.BodyEncodingBinary},
+			value:       map[string]interface{}{"content": base64.StdEncoding.EncodeToString([]byte("png")), "filename": "rex.png"},
+			expected:    "png",
+			contentType: "image/png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, contentType, err := encodeBody(tt.body, tt.value, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(encoded))
+			assert.Equal(t, tt.contentType, contentType)
+		})
+	}
+}
+
+func TestEncodeBody_Multipart(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("good dog"), 0644))
+
+	body := &parser.RequestBody{MediaType: "multipart/form-data", Encoding: parser.BodyEncodingMultipart, FileFields: []string{"files"}}
+	encoded, contentType, err := encodeBody(body, map[string]interface{}{
+		"name":  "Rex",
+		"owner": map[string]interface{}{"id": float64(1)},
+		"files": []interface{}{
+			map[string]interface{}{"content": base64.StdEncoding.EncodeToString([]byte("woof")), "filename": "bark.bin", "contentType": "application/octet-stream"},
+			map[string]interface{}{"path": "notes.txt"},
+		},
+	}, []string{dir})
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/form-data", mediaType)
+
+	type part struct{ name, filename, contentType, content string }
+	var parts []part
+	reader := multipart.NewReader(bytes.NewReader(encoded), params["boundary"])
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(p)
+		require.NoError(t, err)
+		parts = append(parts, part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), string(content)})
+	}
+
+	assert.Equal(t, []part{
+		{"files", "bark.bin", "application/octet-stream", "woof"},
+		{"files", "notes.txt", "text/plain; charset=utf-8", "good dog"},
+		{"name", "", "", "Rex"},
+		{"owner", "", "application/json", `{"id":1}`},
+	}, parts)
+}
+
+func TestResolveUploadPath(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "uploads")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ok.txt"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "secret.txt"), nil, 0644))
+	require.NoError(t, os.Symlink(filepath.Join(root, "secret.txt"), filepath.Join(dir, "link.txt")))
+
+	resolved, err := resolveUploadPath("ok.txt", []string{dir})
+	require.NoError(t, err)
+	assert.Equal(t, "ok.txt", filepath.Base(resolved))
+
+	_, err = resolveUploadPath(filepath.Join(dir, "ok.txt"), []string{dir})
+	assert.NoError(t, err)
+
+	for _, path := range []string{"../secret.txt", filepath.Join(root, "secret.txt"), "link.txt"} {
+		_, err = resolveUploadPath(path, []string{dir})
+		assert.ErrorContains(t, err, "is not inside an upload directory", path)
+	}
+
+	_, err = resolveUploadPath("ok.txt", nil)
+	assert.ErrorContains(t, err, "no upload directory is configured")
+}
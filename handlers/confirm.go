@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
+	"github.com/evisdrenova/axon-server/server"
+	"github.com/google/uuid"
+)
+
+// ConfirmationTokenArgument is the argument an unsafe tool is called again with once the user confirmed the call
+const ConfirmationTokenArgument = "confirmation_token"
+
+// DefaultConfirmationTTL is how long a confirmation token stays valid unless the gate sets its own
+const DefaultConfirmationTTL = 5 * time.Minute
+
+// ConfirmationGate holds back calls of unsafe tools until they are confirmed
+// The first call returns a token and a summary of the request, the request is only sent when the tool is
+// called again with the same arguments and the token
+type ConfirmationGate struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]pendingCall
+}
+
+// pendingCall is a call waiting for confirmation
+type pendingCall struct {
+	toolName  string
+	arguments [sha256.Size]byte
+	expiresAt time.Time
+}
+
+// NewConfirmationGate creates a gate whose tokens expire after ttl, zero or less means DefaultConfirmationTTL
+func NewConfirmationGate(ttl time.Duration) *ConfirmationGate {
+	if ttl <= 0 {
+		ttl = DefaultConfirmationTTL
+	}
+	return &ConfirmationGate{ttl: ttl, now: time.Now, pending: make(map[string]pendingCall)}
+}
+
+// Gate returns the tool with a confirmation_token argument and a handler that only calls the given handler for
+// confirmed calls. Safe operations are returned as they are.
+func (g *ConfirmationGate) Gate(tool parser.Tool, handler server.ToolHandlerFunc) (mcp.Tool, server.ToolHandlerFunc) {
+	if tool.Operation.Safe() {
+		return tool.Tool, handler
+	}
+
+	gated := tool.Tool
+	gated.Description += "\n\nThis operation has to be confirmed by the user. The first call only returns a summary and a confirmation token."
+	gated.InputSchema.Properties = make(map[string]interface{}, len(tool.InputSchema.Properties)+1)
+	for name, schema := range tool.InputSchema.Properties {
+		gated.InputSchema.Properties[name] = schema
+	}
+	gated.InputSchema.Properties[ConfirmationTokenArgument] = map[string]interface{}{
+		"type":        "string",
+		"description": "Token returned by the first call, only pass it after the user confirmed the call",
+	}
+
+	return gated, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := make(map[string]interface{}, len(request.Params.Arguments))
+		for name, value := range request.Params.Arguments {
+			if name != ConfirmationTokenArgument {
+				arguments[name] = value
+			}
+		}
+		digest, err := hashArguments(arguments)
+		if err != nil {
+			return nil, err
+		}
+
+		// a preview sends nothing, so there is nothing to confirm and no token that could later send it
+		if isDryRunContext(ctx) {
+			preview := request
+			preview.Params.Arguments = arguments
+			return handler(ctx, preview)
+		}
+
+		if token, ok := request.Params.Arguments[ConfirmationTokenArgument].(string); ok && token != "" {
+			if !g.redeem(token, tool.Name, digest) {
+				return mcp.NewToolResultError(fmt.Sprintf("The confirmation token is unknown, expired, already used or was issued for other arguments. Call %s without a token to get a new one.", tool.Name)), nil
+			}
+			confirmed := request
+			confirmed.Params.Arguments = arguments
+			return handler(ctx, confirmed)
+		}
+
+		// the summary is the request the call would send
+		preview, err := handler(ContextWithDryRun(ctx), request)
+		if err != nil || preview.IsError {
+			return preview, err
+		}
+
+		token := g.issue(tool.Name, digest)
+		summary := fmt.Sprintf("%s needs to be confirmed by the user before it runs, nothing was sent yet. "+
+			"Show the user what will happen, and once they agree call %s again with the same arguments and %s set to %q. "+
+			"The token expires in %s.", tool.Name, tool.Name, ConfirmationTokenArgument, token, g.ttl)
+		preview.Content = append([]interface{}{mcp.NewTextContent(summary)}, preview.Content...)
+		return preview, nil
+	}
+}
+
+// Creates a token for a call
+func (g *ConfirmationGate) issue(toolName string, arguments [sha256.Size]byte) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	for token, call := range g.pending {
+		if now.After(call.expiresAt) {
+			delete(g.pending, token)
+		}
+	}
+
+	token := uuid.NewString()
+	g.pending[token] = pendingCall{toolName: toolName, arguments: arguments, expiresAt: now.Add(g.ttl)}
+	return token
+}
+
+// Uses up a token, which is only valid once and for the call it was issued for
+func (g *ConfirmationGate) redeem(token string, toolName string, arguments [sha256.Size]byte) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call, ok := g.pending[token]
+	if !ok || call.toolName != toolName || call.arguments != arguments {
+		return false
+	}
+	delete(g.pending, token)
+	return !g.now().After(call.expiresAt)
+}
+
+// Hashes arguments independently of the order of their keys, encoding/json sorts them
+func hashArguments(arguments map[string]interface{}) ([sha256.Size]byte, error) {
+	encoded, err := json.Marshal(arguments)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(encoded), nil
+}
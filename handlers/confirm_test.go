@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmationGate(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tool := parser.Tool{Operation: parser.Operation{Method: http.MethodDelete, URL: server.URL + "/pets/{id}", Parameters: []parser.Parameter{
+		{Name: "id", In: parser.ParameterInPath, Style: parser.StyleSimple, Required: true},
+	}}}
+	tool.Tool = mcp.NewTool("deletePet", mcp.WithString("id", mcp.Required()))
+
+	gate := NewConfirmationGate(time.Minute)
+	now := time.Now()
+	gate.now = func() time.Time { return now }
+
+	gated, handler := gate.Gate(tool, createHandler(tool, log.New(io.Discard, "", 0), newHandlerConfig(nil)))
+	assert.Contains(t, gated.InputSchema.Properties, ConfirmationTokenArgument)
+	assert.NotContains(t, tool.InputSchema.Properties, ConfirmationTokenArgument, "the original tool isn't modified")
+
+	call := func(arguments map[string]interface{}) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+	tokenPattern := regexp.MustCompile(`confirmation_token set to "([^"]+)"`)
+
+	first := call(map[string]interface{}{"id": "7"})
+	assert.False(t, first.IsError)
+	require.Len(t, first.Content, 2)
+	assert.Contains(t, first.Content[1].(mcp.TextContent).Text, `"url": "`+server.URL+`/pets/7"`)
+	match := tokenPattern.FindStringSubmatch(first.Content[0].(mcp.TextContent).Text)
+	require.NotNil(t, match)
+	token := match[1]
+	assert.Equal(t, int32(0), requests.Load())
+
+	// the token only confirms the call it was issued for
+	other := call(map[string]interface{}{"id": "8", ConfirmationTokenArgument: token})
+	assert.True(t, other.IsError)
+	assert.Equal(t, int32(0), requests.Load())
+
+	confirmed := call(map[string]interface{}{"id": "7", ConfirmationTokenArgument: token})
+	assert.False(t, confirmed.IsError)
+	assert.Equal(t, int32(1), requests.Load())
+
+	reused := call(map[string]interface{}{"id": "7", ConfirmationTokenArgument: token})
+	assert.True(t, reused.IsError)
+	assert.Equal(t, int32(1), requests.Load())
+
+	expiring := call(map[string]interface{}{"id": "7"})
+	token = tokenPattern.FindStringSubmatch(expiring.Content[0].(mcp.TextContent).Text)[1]
+	now = now.Add(2 * time.Minute)
+	expired := call(map[string]interface{}{"id": "7", ConfirmationTokenArgument: token})
+	assert.True(t, expired.IsError)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestConfirmationGate_DryRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL)
+	}))
+	defer server.Close()
+
+	tool := parser.Tool{Operation: parser.Operation{Method: http.MethodDelete, URL: server.URL + "/pets/{id}", Parameters: []parser.Parameter{
+		{Name: "id", In: parser.ParameterInPath, Style: parser.StyleSimple, Required: true},
+	}}}
+	tool.Tool = mcp.NewTool("deletePet", mcp.WithString("id", mcp.Required()))
+
+	gate := NewConfirmationGate(time.Minute)
+	_, handler := gate.Gate(tool, createHandler(tool, log.New(io.Discard, "", 0), newHandlerConfig(nil)))
+
+	for _, arguments := range []map[string]interface{}{
+		{"id": "7"},
+		{"id": "7", ConfirmationTokenArgument: "made-up"},
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := handler(ContextWithDryRun(context.Background()), request)
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		require.Len(t, result.Content, 1)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"url": "`+server.URL+`/pets/7"`)
+		assert.NotContains(t, result.Content[0].(mcp.TextContent).Text, ConfirmationTokenArgument)
+	}
+	assert.Empty(t, gate.pending, "no token is issued for a preview")
+}
+
+func TestConfirmationGate_SafeOperations(t *testing.T) {
+	tool := parser.Tool{Operation: parser.Operation{Method: http.MethodGet}}
+	tool.Tool = mcp.NewTool("listPets")
+
+	gated, _ := NewConfirmationGate(0).Gate(tool, nil)
+	assert.Equal(t, tool.Tool, gated)
+
+	confirm := true
+	tool.Operation.Confirm = &confirm
+	gated, _ = NewConfirmationGate(0).Gate(tool, nil)
+	assert.Contains(t, gated.InputSchema.Properties, ConfirmationTokenArgument)
+}
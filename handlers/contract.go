@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/evisdrenova/axon-server/jsonschema"
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
+)
+
+// Checks a response against what the spec documents for its status and describes every difference
+// Operations whose spec documents no responses at all are never flagged
+func checkResponseContract(operation parser.Operation, resp *http.Response, body []byte) []string {
+	if len(operation.Responses) == 0 || operation.Method == http.MethodHead {
+		return nil
+	}
+
+	documented, ok := operation.ResponseFor(resp.StatusCode)
+	if !ok {
+		statuses := make([]string, 0, len(operation.Responses))
+		for status := range operation.Responses {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		return []string{fmt.Sprintf("status %d isn't documented, the spec lists %s", resp.StatusCode, strings.Join(statuses, ", "))}
+	}
+	if documented.Schema == nil {
+		return nil
+	}
+
+	if len(body) == 0 {
+		return []string{fmt.Sprintf("status %d should have a JSON body but the body is empty", resp.StatusCode)}
+	}
+	if mediaType := responseMediaType(resp, body); !isJSONMediaType(mediaType) {
+		return []string{fmt.Sprintf("status %d should have a JSON body but the Content-Type is %s", resp.StatusCode, mediaType)}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []string{fmt.Sprintf("the body isn't valid JSON: %v", err)}
+	}
+
+	var drift []string
+	for _, violation := range jsonschema.Validate(documented.Schema, value) {
+		if violation.Path == "" {
+			drift = append(drift, "body: "+violation.Message)
+		} else {
+			drift = append(drift, "body."+violation.String())
+		}
+	}
+	return drift
+}
+
+// Adds a warning about contract drift to a tool result
+func flagContractDrift(result *mcp.CallToolResult, toolName string, drift []string) *mcp.CallToolResult {
+	if len(drift) == 0 {
+		return result
+	}
+
+	warning := fmt.Sprintf("Warning: the response of %s doesn't match the API spec:\n- %s", toolName, strings.Join(drift, "\n- "))
+	result.Content = append(result.Content, mcp.NewTextContent(warning))
+	return result
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
+	"github.com/evisdrenova/axon-server/server"
+)
+
+// DiagnosticsURI is the resource the problems found in the specs can be read from
+const DiagnosticsURI = "axon://diagnostics"
+
+// SpecDiagnostic is a diagnostic together with the spec it was found in
+type SpecDiagnostic struct {
+	Spec string `json:"spec"`
+	parser.Diagnostic
+}
+
+// DiagnosticsReport exposes the diagnostics of every spec as a JSON resource, so the model can tell why an
+// operation it expects is missing
+type DiagnosticsReport struct {
+	mu    sync.Mutex
+	specs map[string]parser.Diagnostics
+}
+
+// NewDiagnosticsReport registers the report with the server, which needs resource capabilities
+func NewDiagnosticsReport(s *server.MCPServer) *DiagnosticsReport {
+	report := &DiagnosticsReport{specs: make(map[string]parser.Diagnostics)}
+
+	resource := mcp.NewResource(
+		DiagnosticsURI,
+		"Spec diagnostics",
+		mcp.WithResourceDescription("Problems found while converting the API specs to tools, with the JSON pointer of the part of the spec each one is about"),
+		mcp.WithMIMEType("application/json"),
+	)
+	s.AddResource(resource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]interface{}, error) {
+		content, err := json.MarshalIndent(report.Diagnostics(), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{mcp.TextResourceContents{
+			ResourceContents: mcp.ResourceContents{URI: DiagnosticsURI, MIMEType: "application/json"},
+			Text:             string(content),
+		}}, nil
+	})
+
+	return report
+}
+
+// Set replaces the diagnostics of a spec
+func (r *DiagnosticsReport) Set(spec string, diagnostics parser.Diagnostics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.specs[spec] = diagnostics
+}
+
+// Diagnostics returns the diagnostics of every spec, ordered by spec
+func (r *DiagnosticsReport) Diagnostics() []SpecDiagnostic {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	specs := make([]string, 0, len(r.specs))
+	for spec := range r.specs {
+		specs = append(specs, spec)
+	}
+	sort.Strings(specs)
+
+	all := []SpecDiagnostic{}
+	for _, spec := range specs {
+		for _, diagnostic := range r.specs[spec] {
+			all = append(all, SpecDiagnostic{Spec: spec, Diagnostic: diagnostic})
+		}
+	}
+	return all
+}
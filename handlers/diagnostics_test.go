@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
+	mcpserver "github.com/evisdrenova/axon-server/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsReport(t *testing.T) {
+	mcpServer := mcpserver.NewMCPServer("test", "1.0.0", mcpserver.WithResourceCapabilities(false, false))
+	report := NewDiagnosticsReport(mcpServer)
+
+	empty, ok := readResource(t, mcpServer, DiagnosticsURI).(mcp.TextResourceContents)
+	require.True(t, ok)
+	assert.Equal(t, "[]", empty.Text)
+
+	report.Set("weather.json", parser.Diagnostics{
+		{Severity: parser.SeverityWarning, Pointer: "/paths/~1alerts/get", Message: "operation has no operationId, the tool is named get_alerts"},
+	})
+	report.Set("petstore.json", parser.Diagnostics{
+		{Severity: parser.SeverityError, Pointer: "/info", Message: "value of version must be a non-empty string"},
+	})
+
+	contents, ok := readResource(t, mcpServer, DiagnosticsURI).(mcp.TextResourceContents)
+	require.True(t, ok)
+	assert.Equal(t, "application/json", contents.MIMEType)
+	assert.JSONEq(t, `[
+		{"spec": "petstore.json", "severity": "error", "pointer": "/info", "message": "value of version must be a non-empty string"},
+		{"spec": "weather.json", "severity": "warning", "pointer": "/paths/~1alerts/get", "message": "operation has no operationId, the tool is named get_alerts"}
+	]`, contents.Text)
+
+	// setting a spec again replaces its diagnostics
+	report.Set("petstore.json", nil)
+	assert.Len(t, report.Diagnostics(), 1)
+}
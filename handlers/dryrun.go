@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
+	"github.com/evisdrenova/axon-server/server"
+)
+
+// PreviewToolName is the name of the tool returned by NewPreviewTool
+const PreviewToolName = "preview_request"
+
+type dryRunKey struct{}
+
+// ContextWithDryRun makes the tool handler called with the context return the request it built instead of sending it
+func ContextWithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+func isDryRun(ctx context.Context, config *handlerConfig) bool {
+	return config.dryRun || isDryRunContext(ctx)
+}
+
+func isDryRunContext(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// builtRequest is what a dry run shows of the request it didn't send
+type builtRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is the JSON body itself, or the body as a string for every other media type
+	Body interface{} `json:"body,omitempty"`
+}
+
+// Describes a request that was built but not sent, with its credentials masked
+func buildDryRunResult(tool parser.Tool, req *http.Request, body []byte, defaultHeaders map[string]string) *mcp.CallToolResult {
+	masked := redactRequest(req, tool.Operation, defaultHeaders)
+
+	built := builtRequest{
+		Method: masked.Method,
+		URL:    masked.URL.String(),
+	}
+	if len(masked.Header) > 0 {
+		built.Headers = make(map[string]string, len(masked.Header))
+		for name, values := range masked.Header {
+			built.Headers[name] = strings.Join(values, ", ")
+		}
+	}
+	if body != nil {
+		if mediaType := responseMediaType(&http.Response{Header: masked.Header}, body); isJSONMediaType(mediaType) && json.Valid(body) {
+			built.Body = json.RawMessage(body)
+		} else {
+			built.Body = describeBody(tool.Operation.Body, body)
+		}
+	}
+
+	encoded, err := json.MarshalIndent(built, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to describe request: %v", err))
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Dry run, %s was not sent:\n\n%s", tool.Name, encoded))
+}
+
+// NewPreviewTool creates a tool that shows the request another tool of the server would send for the given
+// arguments, without sending it
+func NewPreviewTool(s *server.MCPServer) (mcp.Tool, server.ToolHandlerFunc) {
+	tool := mcp.NewTool(PreviewToolName,
+		mcp.WithDescription("Shows the HTTP request another tool would send for the given arguments, without sending it"),
+		mcp.WithString("tool", mcp.Required(), mcp.Description("Name of the tool to preview")),
+		mcp.WithObject("arguments", mcp.Description("Arguments for the tool")),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name, _ := request.Params.Arguments["tool"].(string)
+		if name == PreviewToolName || !s.HasTool(name) {
+			return mcp.NewToolResultError(fmt.Sprintf("Unknown tool %q", name)), nil
+		}
+
+		// the call goes through the server so the arguments are validated like those of a real call
+		message, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": mcp.JSONRPC_VERSION,
+			"id":      1,
+			"method":  "tools/call",
+			"params": map[string]interface{}{
+				"name":      name,
+				"arguments": request.Params.Arguments["arguments"],
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		switch response := s.HandleMessage(ContextWithDryRun(ctx), message).(type) {
+		case mcp.JSONRPCResponse:
+			if result, ok := response.Result.(*mcp.CallToolResult); ok {
+				return result, nil
+			}
+			return nil, fmt.Errorf("unexpected result %T", response.Result)
+		case mcp.JSONRPCError:
+			return mcp.NewToolResultError(response.Error.Message), nil
+		default:
+			return nil, fmt.Errorf("unexpected response %T", response)
+		}
+	}
+
+	return tool, handler
+}
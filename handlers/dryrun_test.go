@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evisdrenova/axon-server/handlers/auth"
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
+	mcpserver "github.com/evisdrenova/axon-server/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dryRunOperation() parser.Operation {
+	return parser.Operation{
+		Method: http.MethodPost,
+		// nothing listens here, a request that is sent fails
+		URL: "http://127.0.0.1:1/pets/{id}",
+		Parameters: []parser.Parameter{
+			{Name: "id", In: parser.ParameterInPath, Style: parser.StyleSimple, Required: true},
+		},
+		Security: []parser.SecurityRequirement{{
+			{Name: "api_key", Type: parser.SecurityTypeAPIKey, In: parser.ParameterInHeader, ParamName: "X-API-Key"},
+		}},
+		Body: &parser.RequestBody{MediaType: "application/json", Encoding: parser.BodyEncodingJSON},
+	}
+}
+
+func TestHandler_DryRun(t *testing.T) {
+	authenticator := auth.NewAuthenticator(map[string]auth.Credentials{"api_key": {APIKey: "secret"}})
+
+	result := callTool(t, dryRunOperation(), map[string]interface{}{
+		"id":   "7",
+		"body": map[string]interface{}{"name": "Rex"},
+	}, WithDryRun(), WithAuthenticator(authenticator))
+
+	assert.False(t, result.IsError)
+	assert.Equal(t, `Dry run, testTool was not sent:
+
+{
+  "method": "POST",
+  "url": "http://127.0.0.1:1/pets/7",
+  "headers": {
+    "Content-Type": "application/json",
+    "X-Api-Key": "REDACTED"
+  },
+  "body": {
+    "name": "Rex"
+  }
+}`, resultText(t, result))
+}
+
+func TestPreviewTool(t *testing.T) {
+	s := mcpserver.NewMCPServer("test", "1.0.0", mcpserver.WithArgumentValidation())
+
+	tool := parser.Tool{Operation: dryRunOperation()}
+	tool.Tool = mcp.NewTool("updatePet", mcp.WithString("id", mcp.Required()), mcp.WithObject("body"))
+	s.AddTool(tool.Tool, createHandler(tool, log.New(io.Discard, "", 0), newHandlerConfig(nil)))
+	s.AddTool(NewPreviewTool(s))
+
+	preview := func(arguments map[string]interface{}) *mcp.CallToolResult {
+		t.Helper()
+		_, handler := NewPreviewTool(s)
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := preview(map[string]interface{}{"tool": "updatePet", "arguments": map[string]interface{}{"id": "7"}})
+	assert.False(t, result.IsError)
+	assert.Contains(t, resultText(t, result), `"url": "http://127.0.0.1:1/pets/7"`)
+
+	invalid := preview(map[string]interface{}{"tool": "updatePet", "arguments": map[string]interface{}{}})
+	assert.True(t, invalid.IsError)
+	assert.Contains(t, resultText(t, invalid), "Invalid arguments for updatePet")
+
+	unknown := preview(map[string]interface{}{"tool": PreviewToolName})
+	assert.Equal(t, `Unknown tool "preview_request"`, resultText(t, unknown))
+
+	// without the dry run the request is sent, and fails
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"id": "7"}
+	sent, err := createHandler(tool, log.New(io.Discard, "", 0), newHandlerConfig(nil))(context.Background(), request)
+	require.NoError(t, err)
+	assert.Contains(t, resultText(t, sent), "Request failed")
+}
+
+func TestHandler_DryRunOAuth2(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a dry run must not request a token")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer tokenServer.Close()
+
+	operation := dryRunOperation()
+	operation.Security = []parser.SecurityRequirement{{
+		{Name: "petstore_auth", Type: parser.SecurityTypeOAuth2, TokenURL: tokenServer.URL},
+	}}
+	arguments := map[string]interface{}{"id": "7", "body": map[string]interface{}{"name": "Rex"}}
+
+	// no credentials at all, the request is still shown
+	missing := callTool(t, operation, arguments, WithDryRun(), WithAuthenticator(auth.NewAuthenticator(nil)))
+	assert.False(t, missing.IsError)
+	assert.Contains(t, resultText(t, missing), `"Authorization": "REDACTED"`)
+
+	// client credentials that would need a token request
+	configured := auth.NewAuthenticator(map[string]auth.Credentials{"petstore_auth": {ClientID: "id", ClientSecret: "secret"}})
+	withCredentials := callTool(t, operation, arguments, WithDryRun(), WithAuthenticator(configured))
+	assert.False(t, withCredentials.IsError)
+	assert.Contains(t, resultText(t, withCredentials), `"Authorization": "REDACTED"`)
+
+	// the preview tool makes any call a dry run
+	s := mcpserver.NewMCPServer("test", "1.0.0")
+	tool := parser.Tool{Operation: operation}
+	tool.Tool = mcp.NewTool("updatePet", mcp.WithString("id", mcp.Required()), mcp.WithObject("body"))
+	s.AddTool(tool.Tool, createHandler(tool, log.New(io.Discard, "", 0), newHandlerConfig([]HandlerOption{WithAuthenticator(configured)})))
+	_, preview := NewPreviewTool(s)
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"tool": "updatePet", "arguments": arguments}
+	previewed, err := preview(context.Background(), request)
+	require.NoError(t, err)
+	assert.False(t, previewed.IsError)
+	assert.Contains(t, resultText(t, previewed), `"Authorization": "REDACTED"`)
+}
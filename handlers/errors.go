@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
+)
+
+// errorHeaders are the response headers kept in an error result, since they tell the model when or how to try again
+var errorHeaders = []string{"Retry-After", "Request-Id", "X-Request-Id", "X-Correlation-Id", "X-Amzn-Requestid", "Traceparent"}
+
+// errorHeaderPrefixes match the families of rate limit headers
+var errorHeaderPrefixes = []string{"Ratelimit", "X-Ratelimit-", "X-Rate-Limit-"}
+
+// httpError is what the model gets to see of an error response
+type httpError struct {
+	Status     int    `json:"status"`
+	StatusText string `json:"statusText,omitempty"`
+	// Description is what the spec says the status means for this operation
+	Description string            `json:"description,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	// Problem holds an RFC 7807 problem details body, Body holds any other body
+	Problem map[string]interface{} `json:"problem,omitempty"`
+	Body    interface{}            `json:"body,omitempty"`
+}
+
+// Turns a 4xx or 5xx response into an error result with the status, the headers that matter, the parsed body
+// and the meaning the spec gives to the status
+func buildErrorResult(tool parser.Tool, resp *http.Response, body []byte, config *handlerConfig) *mcp.CallToolResult {
+	details := httpError{
+		Status:     resp.StatusCode,
+		StatusText: http.StatusText(resp.StatusCode),
+		Headers:    selectErrorHeaders(resp.Header),
+	}
+	if documented, ok := tool.Operation.ResponseFor(resp.StatusCode); ok {
+		details.Description = documented.Description
+	}
+
+	if len(body) > 0 {
+		mediaType := responseMediaType(resp, body)
+		var parsed interface{}
+		switch {
+		case mediaType == "application/problem+json" && json.Unmarshal(body, &parsed) == nil:
+			if problem, ok := parsed.(map[string]interface{}); ok {
+				details.Problem = problem
+			} else {
+				details.Body = parsed
+			}
+		case isJSONMediaType(mediaType) && json.Unmarshal(body, &parsed) == nil:
+			details.Body = parsed
+		case isTextMediaType(mediaType):
+			text := string(body)
+			if config.maxResponseSize > 0 && len(text) > config.maxResponseSize {
+				text = truncateUTF8(text, config.maxResponseSize) + fmt.Sprintf(" [truncated to %d of %d bytes]", config.maxResponseSize, len(body))
+			}
+			details.Body = text
+		default:
+			details.Body = fmt.Sprintf("<%d bytes of %s>", len(body), mediaType)
+		}
+	}
+
+	encoded, err := json.MarshalIndent(details, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Request failed with status %d: %s", resp.StatusCode, string(body)))
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("%s\n\n%s", summarizeError(details), encoded))
+}
+
+// Sums up an error response in one line, using the problem details or the spec's description when there are any
+func summarizeError(details httpError) string {
+	summary := fmt.Sprintf("Request failed with status %d", details.Status)
+	if details.StatusText != "" {
+		summary += " " + details.StatusText
+	}
+
+	var reason string
+	if title, ok := details.Problem["title"].(string); ok && title != "" {
+		reason = title
+		if detail, ok := details.Problem["detail"].(string); ok && detail != "" {
+			reason += ": " + detail
+		}
+	} else if details.Description != "" {
+		reason = details.Description
+	}
+	if reason != "" {
+		summary += ": " + reason
+	}
+	return summary
+}
+
+// Picks the headers of an error response worth passing on to the model
+func selectErrorHeaders(header http.Header) map[string]string {
+	selected := make(map[string]string)
+	for name, values := range header {
+		if keepErrorHeader(name) {
+			selected[name] = strings.Join(values, ", ")
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	return selected
+}
+
+func keepErrorHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	for _, header := range errorHeaders {
+		if name == header {
+			return true
+		}
+	}
+	for _, prefix := range errorHeaderPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,17 +3,16 @@ package handlers
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/http/httputil"
 	"os"
-	"strings"
 
 	"github.com/evisdrenova/axon-server/handlers/logger"
 	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
 	"github.com/evisdrenova/axon-server/server"
 )
 
@@ -22,7 +21,7 @@ import (
 // Creates a higher level funciton that encapsulates the logger and handler
 // We use a logger here to test the handler
 // We could return all of this to the Claude developer tools but i find that to be more annoying, so for now, we're just logger to an external file
-func CreateOpenAPIMCPToolHandler(tool mcp.Tool) server.ToolHandlerFunc {
+func CreateOpenAPIMCPToolHandler(tool parser.Tool, opts ...HandlerOption) server.ToolHandlerFunc {
 	// Try to create file logger
 	logger, err := logger.CreateFileLogger()
 	if err != nil {
@@ -31,75 +30,112 @@ func CreateOpenAPIMCPToolHandler(tool mcp.Tool) server.ToolHandlerFunc {
 		log.Printf("Falling back to stderr logging due to error: %v", err)
 	}
 
+	config := newHandlerConfig(opts)
+
 	// Return the handler with the appropriate logger
-	return createHandler(tool, logger)
+	return createHandler(tool, logger, config)
 }
 
 // Handler that spins up an http server that claude actually calls as part of the MCP process
 // this handler can really be anything! It doesn't have to be an http server, it can be a wasm module, or anything else!
-func createHandler(tool mcp.Tool, logger *log.Logger) server.ToolHandlerFunc {
+func createHandler(tool parser.Tool, logger *log.Logger, config *handlerConfig) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		client := &http.Client{}
+		client := &http.Client{Transport: config.transport, Timeout: config.timeout}
+
+		arguments := withFixedArguments(tool.Operation, request.Params.Arguments)
 
-		schema := tool.InputSchema.Properties
-		endpoint, ok := schema["endpoint"].(map[string]interface{})
-		if !ok || endpoint["const"] == nil {
-			return mcp.NewToolResultError("Endpoint configuration not found in tool schema"), nil
+		endpointStr, err := buildRequestURL(tool.Operation, arguments)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to build request URL: %v", err)), nil
 		}
 
-		method, ok := schema["method"].(map[string]interface{})
-		if !ok || method["const"] == nil {
-			return mcp.NewToolResultError("Method configuration not found in tool schema"), nil
+		var body []byte
+		var contentType string
+		if bodyData, ok := arguments["body"]; ok {
+			body, contentType, err = encodeBody(tool.Operation.Body, bodyData, config.uploadDirs)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to encode request body: %v", err)), nil
+			}
 		}
 
-		endpointStr := endpoint["const"].(string)
-		methodStr := method["const"].(string)
+		// The request is built by a closure so it can be rebuilt when it has to be sent again with fresh credentials
+		newRequest := func() (*http.Request, error) {
+			var reqBody io.Reader
+			if body != nil {
+				reqBody = bytes.NewReader(body)
+			}
+
+			req, err := http.NewRequestWithContext(ctx, tool.Operation.Method, endpointStr, reqBody)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to create request: %v", err)
+			}
+
+			applyHeaderParameters(req, tool.Operation, arguments)
+
+			if reqBody != nil {
+				req.Header.Set("Content-Type", contentType)
+			}
 
-		for paramName, paramValue := range request.Params.Arguments {
-			if paramName != "body" && paramName != "endpoint" && paramName != "method" {
-				placeholder := fmt.Sprintf("{%s}", paramName)
-				if strings.Contains(endpointStr, placeholder) {
-					endpointStr = strings.ReplaceAll(endpointStr, placeholder, fmt.Sprint(paramValue))
+			if config.authenticator != nil {
+				// a dry run only shows where the credentials go, it neither needs them nor fetches tokens
+				if isDryRun(ctx, config) {
+					config.authenticator.Preview(req, tool.Operation.Security)
+				} else if err := config.authenticator.Apply(ctx, req, tool.Operation.Security); err != nil {
+					return nil, fmt.Errorf("Failed to authenticate request: %v", err)
 				}
 			}
-		}
 
-		var reqBody io.Reader
-		var bodyJSON []byte
-		var err error
-		if bodyData, ok := request.Params.Arguments["body"]; ok {
-			bodyJSON, err = json.MarshalIndent(bodyData, "", "  ")
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal request body: %v", err)), nil
+			for name, value := range config.defaultHeaders {
+				if req.Header.Get(name) == "" {
+					req.Header.Set(name, value)
+				}
 			}
-			reqBody = bytes.NewBuffer(bodyJSON)
-		}
 
-		req, err := http.NewRequestWithContext(ctx, methodStr, endpointStr, reqBody)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create request: %v", err)), nil
+			return req, nil
 		}
 
-		if reqBody != nil {
-			req.Header.Set("Content-Type", "application/json")
+		req, err := newRequest()
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		// Log request details
-		reqDump, err := httputil.DumpRequestOut(req, true)
+		// Log request details, with the credentials masked
+		reqDump, err := httputil.DumpRequestOut(redactRequest(req, tool.Operation, config.defaultHeaders), false)
 		if err != nil {
 			logger.Printf("Error dumping request: %v", err)
 		} else {
 			logger.Printf("REQUEST:\n%s\n", string(reqDump))
-			if bodyJSON != nil {
-				logger.Printf("REQUEST BODY:\n%s\n", string(bodyJSON))
+			if body != nil {
+				logger.Printf("REQUEST BODY:\n%s\n", describeBody(tool.Operation.Body, body))
 			}
 		}
 
-		resp, err := client.Do(req)
+		if isDryRun(ctx, config) {
+			logger.Printf("DRY RUN: %s was not sent\n", tool.Name)
+			return buildDryRunResult(tool, req, body, config.defaultHeaders), nil
+		}
+
+		resp, err := sendRequest(ctx, client, req, newRequest, config, logger)
 		if err != nil {
 			logger.Printf("REQUEST ERROR: %v\n", err)
 			return mcp.NewToolResultError(fmt.Sprintf("Request failed: %v", err)), nil
 		}
+
+		// A rejected OAuth2 token may have been revoked before it expired, so fetch a new one and try once more
+		if resp.StatusCode == http.StatusUnauthorized && config.authenticator != nil && config.authenticator.Invalidate(tool.Operation.Security) {
+			resp.Body.Close()
+			logger.Printf("Request was unauthorized, retrying with a fresh token\n")
+
+			req, err = newRequest()
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			resp, err = sendRequest(ctx, client, req, newRequest, config, logger)
+			if err != nil {
+				logger.Printf("REQUEST ERROR: %v\n", err)
+				return mcp.NewToolResultError(fmt.Sprintf("Request failed: %v", err)), nil
+			}
+		}
 		defer resp.Body.Close()
 
 		// Log response details
@@ -115,18 +151,20 @@ func createHandler(tool mcp.Tool, logger *log.Logger) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to read response: %v", err)), nil
 		}
 
-		if resp.StatusCode >= 400 {
-			logger.Printf("ERROR RESPONSE: Status %d - %s\n", resp.StatusCode, string(respBody))
-			return mcp.NewToolResultError(fmt.Sprintf("Request failed with status %d: %s", resp.StatusCode, string(respBody))), nil
+		// A backend that returns something other than what its spec promises is flagged, but the result is still returned
+		var drift []string
+		if config.validateResponses {
+			drift = checkResponseContract(tool.Operation, resp, respBody)
+			for _, difference := range drift {
+				logger.Printf("CONTRACT DRIFT: %s: %s\n", tool.Name, difference)
+			}
 		}
 
-		var prettyJSON bytes.Buffer
-		if err := json.Indent(&prettyJSON, respBody, "", "  "); err == nil {
-			logger.Printf("RESPONSE BODY (JSON):\n%s\n", prettyJSON.String())
-			return mcp.NewToolResultText(prettyJSON.String()), nil
+		if resp.StatusCode >= 400 {
+			logger.Printf("ERROR RESPONSE: Status %d - %s\n", resp.StatusCode, string(respBody))
+			return flagContractDrift(buildErrorResult(tool, resp, respBody, config), tool.Name, drift), nil
 		}
 
-		logger.Printf("RESPONSE BODY (Raw):\n%s\n", string(respBody))
-		return mcp.NewToolResultText(string(respBody)), nil
+		return flagContractDrift(buildToolResult(tool, resp, respBody, config, logger), tool.Name, drift), nil
 	}
 }
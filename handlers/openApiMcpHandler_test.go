@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
+	mcpserver "github.com/evisdrenova/axon-server/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Calls a tool handler for the operation with the given arguments
+func callTool(t *testing.T, operation parser.Operation, arguments map[string]interface{}, opts ...HandlerOption) *mcp.CallToolResult {
+	t.Helper()
+
+	tool := parser.Tool{Operation: operation}
+	tool.Name = "testTool"
+	handler := createHandler(tool, log.New(io.Discard, "", 0), newHandlerConfig(opts))
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = arguments
+	result, err := handler(context.Background(), request)
+	require.NoError(t, err)
+	return result
+}
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.Len(t, result.Content, 1)
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
+
+func TestHandler_EmptyResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		w.Header().Set("ETag", `"abc"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	result := callTool(t, parser.Operation{Method: http.MethodHead, URL: server.URL + "/pets/{id}", Parameters: []parser.Parameter{
+		{Name: "id", In: parser.ParameterInPath, Style: parser.StyleSimple, Required: true},
+	}}, map[string]interface{}{"id": "1"})
+
+	assert.False(t, result.IsError)
+	text := resultText(t, result)
+	assert.Contains(t, text, `"status": 200`)
+	assert.Contains(t, text, `"Etag": "\"abc\""`)
+}
+
+func TestHandler_ResponseContent(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nimage data")
+	pdf := []byte("%PDF-1.4 document")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(png)
+		case "/pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write(pdf)
+		case "/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"name":"Rex"}`))
+		}
+	}))
+	defer server.Close()
+
+	image := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL + "/image"}, nil)
+	require.Len(t, image.Content, 2)
+	assert.Equal(t, mcp.ImageContent{Type: "image", Data: base64.StdEncoding.EncodeToString(png), MIMEType: "image/png"}, image.Content[1])
+
+	document := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL + "/pdf?api_key=secret"}, nil)
+	require.Len(t, document.Content, 2)
+	assert.Equal(t, mcp.EmbeddedResource{
+		Type: "resource",
+		Resource: mcp.BlobResourceContents{
+			ResourceContents: mcp.ResourceContents{URI: server.URL + "/pdf", MIMEType: "application/pdf"},
+			Blob:             base64.StdEncoding.EncodeToString(pdf),
+		},
+	}, document.Content[1])
+
+	text := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL + "/json"}, nil)
+	assert.Equal(t, "{\n  \"name\": \"Rex\"\n}", resultText(t, text))
+}
+
+func TestHandler_LargeResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/text" {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("héllo world"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("0123456789abcdef"))
+	}))
+	defer server.Close()
+
+	text := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL + "/text"}, nil, WithMaxResponseSize(2))
+	// the cut never splits the two byte é
+	assert.Equal(t, "h\n\n[Response truncated to 2 of 12 bytes. Narrow the request, e.g. with paging or filter parameters, to see the rest.]", resultText(t, text))
+
+	mcpServer := mcpserver.NewMCPServer("test", "1.0.0", mcpserver.WithResourceCapabilities(false, false))
+	store := NewResponseStore(mcpServer, 1)
+
+	stored := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL + "/text"}, nil, WithMaxResponseSize(6), WithResponseStore(store))
+	assert.Equal(t, "héllo\n\n[Response truncated to 6 of 12 bytes. The full response can be read from the resource axon://responses/1.]", resultText(t, stored))
+	assert.Equal(t, mcp.TextResourceContents{
+		ResourceContents: mcp.ResourceContents{URI: "axon://responses/1", MIMEType: "text/plain"},
+		Text:             "héllo world",
+	}, readResource(t, mcpServer, "axon://responses/1"))
+
+	binary := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL + "/binary"}, nil, WithMaxResponseSize(8), WithResponseStore(store))
+	assert.Equal(t, "testTool returned 16 bytes of application/octet-stream, which is more than the 8 bytes a result may hold. It can be read from the resource axon://responses/2.", resultText(t, binary))
+	assert.Equal(t, mcp.BlobResourceContents{
+		ResourceContents: mcp.ResourceContents{URI: "axon://responses/2", MIMEType: "application/octet-stream"},
+		Blob:             base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")),
+	}, readResource(t, mcpServer, "axon://responses/2"))
+
+	// the store only keeps the latest response
+	response := mcpServer.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": {"uri": "axon://responses/1"}}`))
+	assert.IsType(t, mcp.JSONRPCError{}, response)
+}
+
+func readResource(t *testing.T, s *mcpserver.MCPServer, uri string) interface{} {
+	t.Helper()
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": {"uri": "`+uri+`"}}`))
+	result, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "%#v", response)
+	contents := result.Result.(mcp.ReadResourceResult).Contents
+	require.Len(t, contents, 1)
+	return contents[0]
+}
+
+func TestHandler_ResponseValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/pets/1":
+			w.Write([]byte(`{"name":"Rex","age":3}`))
+		case "/pets/2":
+			w.Write([]byte(`{"age":"three"}`))
+		default:
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	operation := func(path string) parser.Operation {
+		return parser.Operation{Method: http.MethodGet, URL: server.URL + path, Responses: map[string]parser.Response{
+			"200": {Description: "The pet", Schema: map[string]interface{}{
+				"type":       "object",
+				"required":   []interface{}{"name"},
+				"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}, "age": map[string]interface{}{"type": "integer"}},
+			}},
+			"404": {Description: "Not found"},
+		}}
+	}
+
+	valid := callTool(t, operation("/pets/1"), nil, WithResponseValidation())
+	assert.Len(t, valid.Content, 1)
+
+	drifted := callTool(t, operation("/pets/2"), nil, WithResponseValidation())
+	require.Len(t, drifted.Content, 2)
+	assert.Equal(t, "Warning: the response of testTool doesn't match the API spec:\n- body.name: is required\n- body.age: expected integer, got string", drifted.Content[1].(mcp.TextContent).Text)
+
+	undocumented := callTool(t, operation("/other"), nil, WithResponseValidation())
+	assert.True(t, undocumented.IsError)
+	require.Len(t, undocumented.Content, 2)
+	assert.Contains(t, undocumented.Content[1].(mcp.TextContent).Text, "status 418 isn't documented, the spec lists 200, 404")
+
+	// without the option nothing is checked
+	unchecked := callTool(t, operation("/pets/2"), nil)
+	assert.Len(t, unchecked.Content, 1)
+}
+
+func TestHandler_ErrorResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/problem":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.Header().Set("X-Request-Id", "req-1")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"type":"https://example.com/out-of-credit","title":"Out of credit","detail":"Your balance is 30","balance":30}`))
+		case "/limited":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "120")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("Set-Cookie", "session=secret")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"slow down"}`))
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("no such pet"))
+		}
+	}))
+	defer server.Close()
+
+	responses := map[string]parser.Response{"404": {Description: "The pet doesn't exist"}}
+
+	problem := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL + "/problem"}, nil)
+	assert.True(t, problem.IsError)
+	assert.Equal(t, `Request failed with status 403 Forbidden: Out of credit: Your balance is 30
+
+{
+  "status": 403,
+  "statusText": "Forbidden",
+  "headers": {
+    "X-Request-Id": "req-1"
+  },
+  "problem": {
+    "balance": 30,
+    "detail": "Your balance is 30",
+    "title": "Out of credit",
+    "type": "https://example.com/out-of-credit"
+  }
+}`, resultText(t, problem))
+
+	limited := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL + "/limited"}, nil)
+	text := resultText(t, limited)
+	assert.Contains(t, text, `"Retry-After": "120"`)
+	assert.Contains(t, text, `"X-Ratelimit-Remaining": "0"`)
+	assert.Contains(t, text, `"message": "slow down"`)
+	assert.NotContains(t, text, "secret")
+
+	missing := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL + "/pets/1", Responses: responses}, nil)
+	assert.Equal(t, `Request failed with status 404 Not Found: The pet doesn't exist
+
+{
+  "status": 404,
+  "statusText": "Not Found",
+  "description": "The pet doesn't exist",
+  "body": "no such pet"
+}`, resultText(t, missing))
+}
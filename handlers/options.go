@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/evisdrenova/axon-server/handlers/auth"
+)
+
+// HandlerOption is a function that configures the handler created by CreateOpenAPIMCPToolHandler.
+type HandlerOption func(*handlerConfig)
+
+// handlerConfig holds the settings shared by every call of a tool handler
+type handlerConfig struct {
+	authenticator *auth.Authenticator
+	timeout       time.Duration
+	uploadDirs    []string
+	// maxResponseSize of zero or less returns responses of any size
+	maxResponseSize int
+	responseStore   *ResponseStore
+	// validateResponses checks responses against the schemas in the spec
+	validateResponses bool
+	retryPolicy       RetryPolicy
+	rateLimiter       *RateLimiter
+	// transport is nil for the default transport
+	transport http.RoundTripper
+	// defaultHeaders are added to every request that doesn't set them
+	defaultHeaders map[string]string
+	dryRun         bool
+}
+
+func newHandlerConfig(opts []HandlerOption) *handlerConfig {
+	config := &handlerConfig{maxResponseSize: DefaultMaxResponseSize}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// WithAuthenticator attaches credentials to each request based on the operation's security requirements
+func WithAuthenticator(authenticator *auth.Authenticator) HandlerOption {
+	return func(c *handlerConfig) {
+		c.authenticator = authenticator
+	}
+}
+
+// WithTimeout limits how long a single upstream request may take, zero means no limit
+// Every retry gets the full timeout again
+func WithTimeout(timeout time.Duration) HandlerOption {
+	return func(c *handlerConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithUploadDirs allows file arguments to be given as paths to files inside these directories
+func WithUploadDirs(dirs ...string) HandlerOption {
+	return func(c *handlerConfig) {
+		c.uploadDirs = append(c.uploadDirs, dirs...)
+	}
+}
+
+// WithMaxResponseSize caps how many bytes of a response are put in a tool result, zero or less means no limit
+// Longer text is truncated with a notice and larger binary content is left out
+func WithMaxResponseSize(size int) HandlerOption {
+	return func(c *handlerConfig) {
+		c.maxResponseSize = size
+	}
+}
+
+// WithResponseStore keeps responses that don't fit in a tool result as resources the model can read
+func WithResponseStore(store *ResponseStore) HandlerOption {
+	return func(c *handlerConfig) {
+		c.responseStore = store
+	}
+}
+
+// WithResponseValidation checks every response against the response schemas in the spec
+// Differences are logged and added to the tool result as a warning, the response itself is still returned
+func WithResponseValidation() HandlerOption {
+	return func(c *handlerConfig) {
+		c.validateResponses = true
+	}
+}
+
+// WithRetryPolicy sends requests that failed for a passing reason again, see RetryPolicy for which ones
+func WithRetryPolicy(policy RetryPolicy) HandlerOption {
+	return func(c *handlerConfig) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithRateLimiter makes every request wait for the limiter before it is sent, retries included
+func WithRateLimiter(limiter *RateLimiter) HandlerOption {
+	return func(c *handlerConfig) {
+		c.rateLimiter = limiter
+	}
+}
+
+// WithTransport sends requests through a transport shared between handlers, see NewTransport
+func WithTransport(transport http.RoundTripper) HandlerOption {
+	return func(c *handlerConfig) {
+		c.transport = transport
+	}
+}
+
+// WithDefaultHeaders adds headers to every request that doesn't already set them, e.g. User-Agent
+// They are only sent to the API, the transport is also used to fetch specs and OAuth2 tokens
+func WithDefaultHeaders(headers map[string]string) HandlerOption {
+	return func(c *handlerConfig) {
+		c.defaultHeaders = headers
+	}
+}
+
+// WithDryRun returns every request the handler builds as its result instead of sending it
+// Calls can also be made dry runs one at a time with ContextWithDryRun
+func WithDryRun() HandlerOption {
+	return func(c *handlerConfig) {
+		c.dryRun = true
+	}
+}
@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/evisdrenova/axon-server/parser"
+)
+
+// Returns the arguments with the pinned values of the operation's parameters and form fields, which the model can't override
+func withFixedArguments(operation parser.Operation, args map[string]interface{}) map[string]interface{} {
+	var merged map[string]interface{}
+	set := func(name string, value interface{}) {
+		if merged == nil {
+			merged = make(map[string]interface{}, len(args)+1)
+			for name, value := range args {
+				merged[name] = value
+			}
+		}
+		merged[name] = value
+	}
+
+	for _, param := range operation.Parameters {
+		if param.Fixed != nil {
+			set(param.ArgumentName(), param.Fixed)
+		}
+	}
+
+	if operation.Body != nil && len(operation.Body.FixedFields) > 0 {
+		fields, _ := args["body"].(map[string]interface{})
+		body := make(map[string]interface{}, len(fields)+len(operation.Body.FixedFields))
+		for name, value := range fields {
+			body[name] = value
+		}
+		for name, value := range operation.Body.FixedFields {
+			body[name] = value
+		}
+		set("body", body)
+	}
+
+	if merged == nil {
+		return args
+	}
+	return merged
+}
+
+// Builds the request URL by substituting the path parameters and appending the query parameters
+func buildRequestURL(operation parser.Operation, args map[string]interface{}) (string, error) {
+	endpoint := operation.URL
+	declared := make(map[string]bool)
+
+	for _, param := range operation.Parameters {
+		if param.In != parser.ParameterInPath {
+			continue
+		}
+		declared[param.ArgumentName()] = true
+
+		value, ok := args[param.ArgumentName()]
+		if !ok || value == nil {
+			continue
+		}
+		placeholder := fmt.Sprintf("{%s}", param.Name)
+		endpoint = strings.ReplaceAll(endpoint, placeholder, serializePathParameter(param, value))
+	}
+
+	// Fall back to plain substitution for placeholders the spec forgot to declare as parameters
+	for paramName, paramValue := range args {
+		if declared[paramName] || paramName == "body" || paramName == "endpoint" || paramName == "method" {
+			continue
+		}
+		placeholder := fmt.Sprintf("{%s}", paramName)
+		if strings.Contains(endpoint, placeholder) {
+			endpoint = strings.ReplaceAll(endpoint, placeholder, url.PathEscape(formatPrimitive(paramValue)))
+		}
+	}
+
+	requestURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %s: %w", endpoint, err)
+	}
+	if !requestURL.IsAbs() {
+		return "", fmt.Errorf("endpoint %s is not an absolute URL, the spec does not declare a server with a host", endpoint)
+	}
+
+	query := requestURL.Query()
+	for _, param := range operation.Parameters {
+		if param.In != parser.ParameterInQuery {
+			continue
+		}
+		if value, ok := args[param.ArgumentName()]; ok && value != nil {
+			serializeQueryParameter(query, param, value)
+		}
+	}
+	requestURL.RawQuery = query.Encode()
+
+	return requestURL.String(), nil
+}
+
+// Sets the header and cookie parameters on the request
+func applyHeaderParameters(req *http.Request, operation parser.Operation, args map[string]interface{}) {
+	for _, param := range operation.Parameters {
+		value, ok := args[param.ArgumentName()]
+		if !ok || value == nil {
+			continue
+		}
+
+		switch param.In {
+		case parser.ParameterInHeader:
+			req.Header.Set(param.Name, serializeSimple(param, value, false))
+		case parser.ParameterInCookie:
+			for _, cookie := range serializeCookieParameter(param, value) {
+				req.AddCookie(cookie)
+			}
+		}
+	}
+}
+
+// Serializes a path parameter using the simple, label or matrix style
+func serializePathParameter(param parser.Parameter, value interface{}) string {
+	switch param.Style {
+	case parser.StyleLabel:
+		separator := ","
+		if param.Explode {
+			separator = "."
+		}
+		if items, ok := asArray(value, true); ok {
+			return "." + strings.Join(items, separator)
+		}
+		if keys, values, ok := asObject(value, true); ok {
+			if param.Explode {
+				return "." + strings.Join(joinPairs(keys, values, "="), ".")
+			}
+			return "." + strings.Join(flattenPairs(keys, values), ",")
+		}
+		return "." + url.PathEscape(formatPrimitive(value))
+
+	case parser.StyleMatrix:
+		name := url.PathEscape(param.Name)
+		if items, ok := asArray(value, true); ok {
+			if param.Explode {
+				return ";" + name + "=" + strings.Join(items, ";"+name+"=")
+			}
+			return ";" + name + "=" + strings.Join(items, ",")
+		}
+		if keys, values, ok := asObject(value, true); ok {
+			if param.Explode {
+				return ";" + strings.Join(joinPairs(keys, values, "="), ";")
+			}
+			return ";" + name + "=" + strings.Join(flattenPairs(keys, values), ",")
+		}
+		return ";" + name + "=" + url.PathEscape(formatPrimitive(value))
+
+	default:
+		return serializeSimple(param, value, true)
+	}
+}
+
+// Serializes a value using the simple style shared by path and header parameters
+func serializeSimple(param parser.Parameter, value interface{}, escape bool) string {
+	if items, ok := asArray(value, escape); ok {
+		return strings.Join(items, ",")
+	}
+	if keys, values, ok := asObject(value, escape); ok {
+		if param.Explode {
+			return strings.Join(joinPairs(keys, values, "="), ",")
+		}
+		return strings.Join(flattenPairs(keys, values), ",")
+	}
+	if escape {
+		return url.PathEscape(formatPrimitive(value))
+	}
+	return formatPrimitive(value)
+}
+
+// Adds a query parameter using the form, spaceDelimited, pipeDelimited or deepObject style
+func serializeQueryParameter(query url.Values, param parser.Parameter, value interface{}) {
+	if param.Style == parser.StyleDeepObject {
+		if _, _, ok := asObject(value, false); ok {
+			addDeepObject(query, param.Name, value)
+			return
+		}
+	}
+
+	separator := ","
+	switch param.Style {
+	case parser.StyleSpaceDelimited:
+		separator = " "
+	case parser.StylePipeDelimited:
+		separator = "|"
+	case parser.StyleTabDelimited:
+		separator = "\t"
+	}
+
+	if items, ok := asArray(value, false); ok {
+		if param.Explode {
+			for _, item := range items {
+				query.Add(param.Name, item)
+			}
+			return
+		}
+		query.Add(param.Name, strings.Join(items, separator))
+		return
+	}
+
+	if keys, values, ok := asObject(value, false); ok {
+		if param.Explode {
+			for i, key := range keys {
+				query.Add(key, values[i])
+			}
+			return
+		}
+		query.Add(param.Name, strings.Join(flattenPairs(keys, values), separator))
+		return
+	}
+
+	query.Add(param.Name, formatPrimitive(value))
+}
+
+// Adds nested object properties as name[key]=value pairs
+func addDeepObject(query url.Values, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			addDeepObject(query, fmt.Sprintf("%s[%s]", prefix, key), v[key])
+		}
+	case []interface{}:
+		for _, item := range v {
+			query.Add(prefix, formatPrimitive(item))
+		}
+	default:
+		query.Add(prefix, formatPrimitive(value))
+	}
+}
+
+// Serializes a cookie parameter using the form style
+func serializeCookieParameter(param parser.Parameter, value interface{}) []*http.Cookie {
+	if items, ok := asArray(value, false); ok {
+		if !param.Explode {
+			return []*http.Cookie{{Name: param.Name, Value: strings.Join(items, ",")}}
+		}
+		cookies := make([]*http.Cookie, 0, len(items))
+		for _, item := range items {
+			cookies = append(cookies, &http.Cookie{Name: param.Name, Value: item})
+		}
+		return cookies
+	}
+
+	if keys, values, ok := asObject(value, false); ok {
+		if !param.Explode {
+			return []*http.Cookie{{Name: param.Name, Value: strings.Join(flattenPairs(keys, values), ",")}}
+		}
+		cookies := make([]*http.Cookie, 0, len(keys))
+		for i, key := range keys {
+			cookies = append(cookies, &http.Cookie{Name: key, Value: values[i]})
+		}
+		return cookies
+	}
+
+	return []*http.Cookie{{Name: param.Name, Value: formatPrimitive(value)}}
+}
+
+// Returns the formatted items if the value is an array
+func asArray(value interface{}, escape bool) ([]string, bool) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, escapeValue(formatPrimitive(item), escape))
+	}
+	return result, true
+}
+
+// Returns the sorted keys and their formatted values if the value is an object
+func asObject(value interface{}, escape bool) ([]string, []string, bool) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, nil, false
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := make([]string, 0, len(keys))
+	for i, key := range keys {
+		values = append(values, escapeValue(formatPrimitive(object[key]), escape))
+		keys[i] = escapeValue(key, escape)
+	}
+	return keys, values, true
+}
+
+func escapeValue(value string, escape bool) string {
+	if escape {
+		return url.PathEscape(value)
+	}
+	return value
+}
+
+// Turns keys and values into key=value pairs
+func joinPairs(keys, values []string, separator string) []string {
+	pairs := make([]string, 0, len(keys))
+	for i, key := range keys {
+		pairs = append(pairs, key+separator+values[i])
+	}
+	return pairs
+}
+
+// Turns keys and values into a single key,value,key,value list
+func flattenPairs(keys, values []string) []string {
+	pairs := make([]string, 0, len(keys)*2)
+	for i, key := range keys {
+		pairs = append(pairs, key, values[i])
+	}
+	return pairs
+}
+
+// Formats a single JSON value the way it should appear in a URL or header
+func formatPrimitive(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	case []interface{}, map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprint(v)
+	}
+}
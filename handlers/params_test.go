@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/evisdrenova/axon-server/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializePathParameter(t *testing.T) {
+	array := []interface{}{float64(3), float64(4), float64(5)}
+	object := map[string]interface{}{"role": "admin", "firstName": "Alex"}
+
+	tests := []struct {
+		name     string
+		style    string
+		explode  bool
+		value    interface{}
+		expected string
+	}{
+		{"simple primitive", parser.StyleSimple, false, float64(5), "5"},
+		{"simple array", parser.StyleSimple, false, array, "3,4,5"},
+		{"simple object", parser.StyleSimple, false, object, "firstName,Alex,role,admin"},
+		{"simple object exploded", parser.StyleSimple, true, object, "firstName=Alex,role=admin"},
+		{"simple escapes reserved characters", parser.StyleSimple, false, "a/b c", "a%2Fb%20c"},
+		{"label primitive", parser.StyleLabel, false, float64(5), ".5"},
+		{"label array", parser.StyleLabel, false, array, ".3,4,5"},
+		{"label array exploded", parser.StyleLabel, true, array, ".3.4.5"},
+		{"label object exploded", parser.StyleLabel, true, object, ".firstName=Alex.role=admin"},
+		{"matrix primitive", parser.StyleMatrix, false, float64(5), ";id=5"},
+		{"matrix array", parser.StyleMatrix, false, array, ";id=3,4,5"},
+		{"matrix array exploded", parser.StyleMatrix, true, array, ";id=3;id=4;id=5"},
+		{"matrix object", parser.StyleMatrix, false, object, ";id=firstName,Alex,role,admin"},
+		{"matrix object exploded", parser.StyleMatrix, true, object, ";firstName=Alex;role=admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param := parser.Parameter{Name: "id", In: parser.ParameterInPath, Style: tt.style, Explode: tt.explode}
+			assert.Equal(t, tt.expected, serializePathParameter(param, tt.value))
+		})
+	}
+}
+
+func TestSerializeQueryParameter(t *testing.T) {
+	array := []interface{}{"3", "4", "5"}
+	object := map[string]interface{}{"role": "admin", "firstName": "Alex"}
+
+	tests := []struct {
+		name     string
+		style    string
+		explode  bool
+		value    interface{}
+		expected url.Values
+	}{
+		{"form primitive", parser.StyleForm, true, float64(1000000), url.Values{"id": {"1000000"}}},
+		{"form boolean", parser.StyleForm, true, true, url.Values{"id": {"true"}}},
+		{"form array", parser.StyleForm, false, array, url.Values{"id": {"3,4,5"}}},
+		{"form array exploded", parser.StyleForm, true, array, url.Values{"id": {"3", "4", "5"}}},
+		{"form object", parser.StyleForm, false, object, url.Values{"id": {"firstName,Alex,role,admin"}}},
+		{"form object exploded", parser.StyleForm, true, object, url.Values{"role": {"admin"}, "firstName": {"Alex"}}},
+		{"space delimited array", parser.StyleSpaceDelimited, false, array, url.Values{"id": {"3 4 5"}}},
+		{"pipe delimited array", parser.StylePipeDelimited, false, array, url.Values{"id": {"3|4|5"}}},
+		{"deep object", parser.StyleDeepObject, true, object, url.Values{"id[role]": {"admin"}, "id[firstName]": {"Alex"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := url.Values{}
+			param := parser.Parameter{Name: "id", In: parser.ParameterInQuery, Style: tt.style, Explode: tt.explode}
+			serializeQueryParameter(query, param, tt.value)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestBuildRequest(t *testing.T) {
+	operation := parser.Operation{
+		Method: "GET",
+		URL:    "https://api.example.com/v1/pets/{petId}?version=2",
+		Parameters: []parser.Parameter{
+			{Name: "petId", In: parser.ParameterInPath, Style: parser.StyleSimple, Required: true},
+			{Name: "status", In: parser.ParameterInQuery, Style: parser.StyleForm, Explode: true},
+			{Name: "tags", In: parser.ParameterInQuery, Style: parser.StyleForm, Explode: false},
+			{Name: "X-Request-Id", In: parser.ParameterInHeader, Style: parser.StyleSimple},
+			{Name: "session", In: parser.ParameterInCookie, Style: parser.StyleForm, Explode: true},
+		},
+	}
+	args := map[string]interface{}{
+		"petId":        float64(42),
+		"status":       []interface{}{"available", "pending"},
+		"tags":         []interface{}{"dog", "cat"},
+		"X-Request-Id": "abc",
+		"session":      "s3cr3t",
+		"endpoint":     operation.URL,
+	}
+
+	endpoint, err := buildRequestURL(operation, args)
+	assert.NoError(t, err)
+
+	parsed, err := url.Parse(endpoint)
+	assert.NoError(t, err)
+	assert.Equal(t, "/v1/pets/42", parsed.Path)
+	assert.Equal(t, url.Values{
+		"version": {"2"},
+		"status":  {"available", "pending"},
+		"tags":    {"dog,cat"},
+	}, parsed.Query())
+
+	req, err := http.NewRequest(operation.Method, endpoint, nil)
+	assert.NoError(t, err)
+	applyHeaderParameters(req, operation, args)
+
+	assert.Equal(t, "abc", req.Header.Get("X-Request-Id"))
+	cookie, err := req.Cookie("session")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", cookie.Value)
+}
+
+func TestBuildRequest_SharedParameterName(t *testing.T) {
+	operation := parser.Operation{
+		Method: "GET",
+		URL:    "https://api.example.com/v1/pets/{id}",
+		Parameters: []parser.Parameter{
+			{Name: "id", In: parser.ParameterInPath, Style: parser.StyleSimple, Required: true, Argument: "path.id"},
+			{Name: "id", In: parser.ParameterInQuery, Style: parser.StyleForm, Explode: true, Argument: "query.id"},
+			{Name: "id", In: parser.ParameterInHeader, Style: parser.StyleSimple, Argument: "header.id"},
+		},
+	}
+	args := map[string]interface{}{
+		"path.id":   float64(42),
+		"query.id":  "owner",
+		"header.id": "abc",
+		"endpoint":  operation.URL,
+	}
+
+	endpoint, err := buildRequestURL(operation, args)
+	assert.NoError(t, err)
+
+	parsed, err := url.Parse(endpoint)
+	assert.NoError(t, err)
+	assert.Equal(t, "/v1/pets/42", parsed.Path)
+	assert.Equal(t, url.Values{"id": {"owner"}}, parsed.Query())
+
+	req, err := http.NewRequest(operation.Method, endpoint, nil)
+	assert.NoError(t, err)
+	applyHeaderParameters(req, operation, args)
+	assert.Equal(t, "abc", req.Header.Get("id"))
+}
+
+func TestWithFixedArguments(t *testing.T) {
+	operation := parser.Operation{Parameters: []parser.Parameter{
+		{Name: "format", In: parser.ParameterInQuery, Style: parser.StyleForm, Fixed: "json"},
+		{Name: "limit", In: parser.ParameterInQuery, Style: parser.StyleForm},
+	}}
+	args := map[string]interface{}{"format": "xml", "limit": float64(5)}
+
+	assert.Equal(t, map[string]interface{}{"format": "json", "limit": float64(5)}, withFixedArguments(operation, args))
+	assert.Equal(t, "xml", args["format"], "the arguments of the call aren't modified")
+
+	unpinned := parser.Operation{Parameters: operation.Parameters[1:]}
+	assert.Equal(t, args, withFixedArguments(unpinned, args))
+
+	form := parser.Operation{Body: &parser.RequestBody{Encoding: parser.BodyEncodingForm, FixedFields: map[string]interface{}{"source": "axon"}}}
+	body := map[string]interface{}{"name": "Rex", "source": "model"}
+	assert.Equal(t, map[string]interface{}{"body": map[string]interface{}{"name": "Rex", "source": "axon"}}, withFixedArguments(form, map[string]interface{}{"body": body}))
+	assert.Equal(t, "model", body["source"], "the body of the call isn't modified")
+	assert.Equal(t, map[string]interface{}{"body": map[string]interface{}{"source": "axon"}}, withFixedArguments(form, map[string]interface{}{}))
+}
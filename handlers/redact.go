@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/evisdrenova/axon-server/parser"
+)
+
+const redacted = "REDACTED"
+
+// Words that mark a default header as a credential, e.g. X-Api-Key or X-Auth-Token
+var secretHeaderWords = []string{"auth", "key", "token", "secret", "password", "session", "cookie", "signature", "credential"}
+
+// Returns a copy of the request with every credential masked so that it is safe to log
+// Default headers from the config are masked when their name looks like a credential
+// The copy shares the body with the original, so it should only be dumped without it
+func redactRequest(req *http.Request, operation parser.Operation, defaultHeaders map[string]string) *http.Request {
+	clone := req.Clone(req.Context())
+
+	for _, header := range []string{"Authorization", "Proxy-Authorization"} {
+		if clone.Header.Get(header) != "" {
+			clone.Header.Set(header, redacted)
+		}
+	}
+	for name := range defaultHeaders {
+		if isSecretHeader(name) && clone.Header.Get(name) != "" {
+			clone.Header.Set(name, redacted)
+		}
+	}
+
+	query := clone.URL.Query()
+	queryChanged := false
+	for _, requirement := range operation.Security {
+		for _, scheme := range requirement {
+			if scheme.Type != parser.SecurityTypeAPIKey {
+				continue
+			}
+
+			switch scheme.In {
+			case parser.ParameterInHeader:
+				if clone.Header.Get(scheme.ParamName) != "" {
+					clone.Header.Set(scheme.ParamName, redacted)
+				}
+			case parser.ParameterInQuery:
+				if query.Has(scheme.ParamName) {
+					query.Set(scheme.ParamName, redacted)
+					queryChanged = true
+				}
+			case parser.ParameterInCookie:
+				cookies := clone.Cookies()
+				clone.Header.Del("Cookie")
+				for _, cookie := range cookies {
+					if strings.EqualFold(cookie.Name, scheme.ParamName) {
+						cookie.Value = redacted
+					}
+					clone.AddCookie(cookie)
+				}
+			}
+		}
+	}
+	if queryChanged {
+		clone.URL.RawQuery = query.Encode()
+	}
+
+	return clone
+}
+
+func isSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range secretHeaderWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
+)
+
+// DefaultMaxResponseSize is how many bytes of a response are put in a tool result unless WithMaxResponseSize says otherwise
+const DefaultMaxResponseSize = 100_000
+
+// Turns a successful response into a tool result based on its Content-Type
+// Images are returned as images, other binary content as an embedded blob and everything else as text
+func buildToolResult(tool parser.Tool, resp *http.Response, body []byte, config *handlerConfig, logger *log.Logger) *mcp.CallToolResult {
+	// HEAD and OPTIONS responses, and plenty of others, only say something through their status and headers
+	if len(body) == 0 {
+		return mcp.NewToolResultText(describeEmptyResponse(resp))
+	}
+
+	mediaType := responseMediaType(resp, body)
+	tooLarge := config.maxResponseSize > 0 && len(body) > config.maxResponseSize
+
+	if !isTextMediaType(mediaType) {
+		logger.Printf("RESPONSE BODY (Binary): %d bytes of %s\n", len(body), mediaType)
+		if tooLarge {
+			return omittedResult(tool, mediaType, body, config)
+		}
+
+		if strings.HasPrefix(mediaType, "image/") {
+			return mcp.NewToolResultImage(
+				fmt.Sprintf("%s returned a %s image of %d bytes", tool.Name, mediaType, len(body)),
+				base64.StdEncoding.EncodeToString(body),
+				mediaType,
+			)
+		}
+
+		return mcp.NewToolResultResource(
+			fmt.Sprintf("%s returned %d bytes of %s", tool.Name, len(body), mediaType),
+			mcp.BlobResourceContents{
+				ResourceContents: mcp.ResourceContents{URI: resourceURI(resp), MIMEType: mediaType},
+				Blob:             base64.StdEncoding.EncodeToString(body),
+			},
+		)
+	}
+
+	text := string(body)
+	var prettyJSON bytes.Buffer
+	if err := json.Indent(&prettyJSON, body, "", "  "); err == nil {
+		text = prettyJSON.String()
+		logger.Printf("RESPONSE BODY (JSON):\n%s\n", text)
+	} else {
+		logger.Printf("RESPONSE BODY (Raw):\n%s\n", text)
+	}
+
+	if config.maxResponseSize > 0 && len(text) > config.maxResponseSize {
+		notice := fmt.Sprintf("\n\n[Response truncated to %d of %d bytes.", config.maxResponseSize, len(text))
+		if config.responseStore != nil {
+			uri := config.responseStore.Stash(tool.Name, mediaType, []byte(text), false)
+			notice += fmt.Sprintf(" The full response can be read from the resource %s.]", uri)
+		} else {
+			notice += " Narrow the request, e.g. with paging or filter parameters, to see the rest.]"
+		}
+		text = truncateUTF8(text, config.maxResponseSize) + notice
+	}
+
+	return mcp.NewToolResultText(text)
+}
+
+// Result for a binary response that is too large to put in a tool result
+func omittedResult(tool parser.Tool, mediaType string, body []byte, config *handlerConfig) *mcp.CallToolResult {
+	text := fmt.Sprintf("%s returned %d bytes of %s, which is more than the %d bytes a result may hold.", tool.Name, len(body), mediaType, config.maxResponseSize)
+	if config.responseStore != nil {
+		uri := config.responseStore.Stash(tool.Name, mediaType, body, true)
+		return mcp.NewToolResultText(text + fmt.Sprintf(" It can be read from the resource %s.", uri))
+	}
+	return mcp.NewToolResultText(text + " It was left out.")
+}
+
+// Gets the media type of a response, sniffing it when the server doesn't say
+func responseMediaType(resp *http.Response, body []byte) string {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// Reports whether content of the media type can be shown to the model as text
+func isTextMediaType(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "application/x-www-form-urlencoded",
+		"application/yaml", "application/x-yaml", "application/x-ndjson", "application/graphql":
+		return true
+	}
+	return false
+}
+
+// Identifies an embedded response by the URL it came from, without the query since it may hold credentials
+func resourceURI(resp *http.Response) string {
+	if resp.Request == nil || resp.Request.URL == nil {
+		return "axon://response"
+	}
+	uri := *resp.Request.URL
+	uri.RawQuery = ""
+	uri.User = nil
+	return uri.String()
+}
+
+// Cuts text to at most size bytes without splitting a character
+func truncateUTF8(text string, size int) string {
+	if len(text) <= size {
+		return text
+	}
+	for size > 0 && !utf8.RuneStart(text[size]) {
+		size--
+	}
+	return text[:size]
+}
+
+// Describes a response without a body by its status and headers
+func describeEmptyResponse(resp *http.Response) string {
+	headers := make(map[string]string, len(resp.Header))
+	for name, values := range resp.Header {
+		headers[name] = strings.Join(values, ", ")
+	}
+
+	description, _ := json.MarshalIndent(map[string]interface{}{
+		"status":  resp.StatusCode,
+		"headers": headers,
+	}, "", "  ")
+	return string(description)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultInitialBackoff is the wait before the first retry unless the policy sets its own
+	DefaultInitialBackoff = 500 * time.Millisecond
+	// DefaultMaxBackoff is the longest wait between two retries unless the policy sets its own
+	DefaultMaxBackoff = 30 * time.Second
+	// DefaultMaxRetryAfter is the longest Retry-After that is waited for unless the policy sets its own
+	DefaultMaxRetryAfter = time.Minute
+)
+
+// RetryPolicy decides when a failed request is sent again
+// Idempotent requests are retried on network errors and 502, 503 and 504 responses, every request is retried on 429
+// since the API turned it away before doing anything
+type RetryPolicy struct {
+	// MaxRetries is how many times a request may be sent again, zero disables retries
+	MaxRetries int
+	// InitialBackoff doubles with every retry up to MaxBackoff, the actual wait is jittered between half and all of it
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxRetryAfter is the longest Retry-After the policy waits for, a response asking for more is returned as is
+	MaxRetryAfter time.Duration
+}
+
+// Works out how long to wait before sending a request again, false means it shouldn't be sent again
+func (p RetryPolicy) delay(retry int, method string, resp *http.Response, err error) (time.Duration, bool) {
+	if retry >= p.MaxRetries {
+		return 0, false
+	}
+
+	if err != nil {
+		if !isIdempotent(method) {
+			return 0, false
+		}
+		return p.backoff(retry), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if !isIdempotent(method) {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		maxRetryAfter := p.MaxRetryAfter
+		if maxRetryAfter <= 0 {
+			maxRetryAfter = DefaultMaxRetryAfter
+		}
+		if wait > maxRetryAfter {
+			return 0, false
+		}
+		return wait, true
+	}
+	return p.backoff(retry), true
+}
+
+// Exponential backoff with jitter, so clients that failed together don't all retry at the same moment
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	if backoff <= 0 {
+		backoff = DefaultInitialBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	for i := 0; i < retry && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	half := backoff / 2
+	return half + rand.N(backoff-half+1)
+}
+
+// Parses a Retry-After header, which is either a number of seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// Sends a request, waiting for the rate limiter first and sending it again as the retry policy allows
+// newRequest builds the request for every retry since a request body can only be read once
+func sendRequest(ctx context.Context, client *http.Client, req *http.Request, newRequest func() (*http.Request, error), config *handlerConfig, logger *log.Logger) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		if config.rateLimiter != nil {
+			if err := config.rateLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := client.Do(req)
+		// a request the caller gave up on is never retried
+		if ctx.Err() != nil {
+			return resp, err
+		}
+
+		wait, ok := config.retryPolicy.delay(retry, req.Method, resp, err)
+		if !ok {
+			return resp, err
+		}
+		if err != nil {
+			logger.Printf("REQUEST ERROR: %v, retrying in %s\n", err, wait)
+		} else {
+			logger.Printf("Request failed with status %d, retrying in %s\n", resp.StatusCode, wait)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		req, err = newRequest()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
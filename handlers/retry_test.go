@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/evisdrenova/axon-server/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Serves the given statuses in turn and counts the requests
+func flakyServer(t *testing.T, retryAfter string, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		status := statuses[len(statuses)-1]
+		if n <= len(statuses) {
+			status = statuses[n-1]
+		}
+		if retryAfter != "" && status != http.StatusOK {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestHandler_Retries(t *testing.T) {
+	policy := WithRetryPolicy(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+
+	t.Run("idempotent request is retried until it succeeds", func(t *testing.T) {
+		server, requests := flakyServer(t, "", http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK)
+		result := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL}, nil, policy)
+		assert.False(t, result.IsError)
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("retries give up after the limit", func(t *testing.T) {
+		server, requests := flakyServer(t, "", http.StatusBadGateway)
+		result := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL}, nil, policy)
+		assert.True(t, result.IsError)
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("non-idempotent request is only retried on 429", func(t *testing.T) {
+		server, requests := flakyServer(t, "", http.StatusServiceUnavailable)
+		callTool(t, parser.Operation{Method: http.MethodPost, URL: server.URL}, map[string]interface{}{"body": map[string]interface{}{}}, policy)
+		assert.Equal(t, int32(1), requests.Load())
+
+		server, requests = flakyServer(t, "0", http.StatusTooManyRequests, http.StatusOK)
+		result := callTool(t, parser.Operation{Method: http.MethodPost, URL: server.URL}, map[string]interface{}{"body": map[string]interface{}{"name": "Rex"}}, policy)
+		assert.False(t, result.IsError)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("a Retry-After over the limit returns the response", func(t *testing.T) {
+		server, requests := flakyServer(t, "3600", http.StatusTooManyRequests)
+		result := callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL}, nil, policy)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), `"Retry-After": "3600"`)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("without a policy nothing is retried", func(t *testing.T) {
+		server, requests := flakyServer(t, "", http.StatusServiceUnavailable)
+		callTool(t, parser.Operation{Method: http.MethodGet, URL: server.URL}, nil)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+
+	for retry, expected := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		wait, ok := policy.delay(retry, http.MethodGet, unavailable, nil)
+		require.True(t, ok)
+		assert.GreaterOrEqual(t, wait, expected/2)
+		assert.LessOrEqual(t, wait, expected)
+	}
+
+	_, ok := policy.delay(5, http.MethodGet, unavailable, nil)
+	assert.False(t, ok)
+
+	_, ok = policy.delay(0, http.MethodPost, nil, assert.AnError)
+	assert.False(t, ok)
+
+	limited := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"7"}}}
+	wait, ok := policy.delay(0, http.MethodPost, limited, nil)
+	require.True(t, ok)
+	assert.Equal(t, 7*time.Second, wait)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	wait, ok := parseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, wait)
+
+	wait, ok = parseRetryAfter("Mon, 01 Jan 2024 12:00:30 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, wait)
+
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(100, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(ctx))
+	require.NoError(t, limiter.Wait(ctx))
+	assert.Less(t, time.Since(start), 5*time.Millisecond, "the burst isn't limited")
+
+	require.NoError(t, limiter.Wait(ctx))
+	assert.GreaterOrEqual(t, time.Since(start), 5*time.Millisecond, "the third request waits for a token")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	limiter = NewRateLimiter(0.001, 1)
+	require.NoError(t, limiter.Wait(cancelled))
+	assert.ErrorIs(t, limiter.Wait(cancelled), context.Canceled)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync"
+
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/server"
+)
+
+// DefaultStoredResponses is how many responses a ResponseStore keeps before it drops the oldest
+const DefaultStoredResponses = 20
+
+// ResponseStore keeps full response bodies as MCP resources, so the model can read responses that were cut
+// short or left out of a tool result
+type ResponseStore struct {
+	server *server.MCPServer
+	limit  int
+
+	mu   sync.Mutex
+	uris []string
+	next int
+}
+
+// NewResponseStore registers stored responses with the server, which needs resource capabilities
+// A limit of zero or less keeps DefaultStoredResponses responses
+func NewResponseStore(s *server.MCPServer, limit int) *ResponseStore {
+	if limit <= 0 {
+		limit = DefaultStoredResponses
+	}
+	return &ResponseStore{server: s, limit: limit}
+}
+
+// Stash stores a response body and returns the URI of the resource it can be read from
+func (r *ResponseStore) Stash(toolName string, mimeType string, body []byte, binary bool) string {
+	r.mu.Lock()
+	r.next++
+	uri := fmt.Sprintf("axon://responses/%d", r.next)
+	r.uris = append(r.uris, uri)
+	var evicted []string
+	if len(r.uris) > r.limit {
+		evicted = r.uris[:len(r.uris)-r.limit]
+		r.uris = append([]string(nil), r.uris[len(r.uris)-r.limit:]...)
+	}
+	r.mu.Unlock()
+
+	for _, old := range evicted {
+		r.server.RemoveResource(old)
+	}
+
+	contents := mcp.ResourceContents{URI: uri, MIMEType: mimeType}
+	var content interface{}
+	if binary {
+		content = mcp.BlobResourceContents{ResourceContents: contents, Blob: base64.StdEncoding.EncodeToString(body)}
+	} else {
+		content = mcp.TextResourceContents{ResourceContents: contents, Text: string(body)}
+	}
+
+	resource := mcp.NewResource(uri, fmt.Sprintf("Response of %s", toolName), mcp.WithMIMEType(mimeType))
+	r.server.AddResource(resource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]interface{}, error) {
+		return []interface{}{content}, nil
+	})
+
+	return uri
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evisdrenova/axon-server/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("User-Agent")))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	get := func(t *testing.T, config TransportConfig) (*http.Response, error) {
+		t.Helper()
+		transport, err := NewTransport(config)
+		require.NoError(t, err)
+		return (&http.Client{Transport: transport}).Get(server.URL)
+	}
+
+	_, err := get(t, TransportConfig{})
+	assert.Error(t, err, "the test server's certificate isn't trusted by default")
+
+	resp, err := get(t, TransportConfig{CAFile: caFile})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = get(t, TransportConfig{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	_, err = NewTransport(TransportConfig{CertFile: caFile})
+	assert.ErrorContains(t, err, "needs both a certificate and a key file")
+
+	_, err = NewTransport(TransportConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.ErrorContains(t, err, "failed to read CA file")
+
+	_, err = NewTransport(TransportConfig{ProxyURL: "::"})
+	assert.ErrorContains(t, err, "invalid proxy URL")
+}
+
+func TestHandler_DefaultHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "axon-test", r.Header.Get("User-Agent"))
+		assert.Equal(t, "acme", r.Header.Get("X-Tenant"))
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		// headers set by the request itself win
+		assert.Equal(t, "search", r.Header.Get("X-Team"))
+	}))
+	defer server.Close()
+
+	headers := map[string]string{"User-Agent": "axon-test", "X-Tenant": "acme", "X-Api-Key": "secret", "X-Team": "payments"}
+	operation := parser.Operation{Method: http.MethodGet, URL: server.URL + "/pets", Parameters: []parser.Parameter{
+		{Name: "X-Team", In: parser.ParameterInHeader, Style: parser.StyleSimple},
+	}}
+	arguments := map[string]interface{}{"X-Team": "search"}
+
+	result := callTool(t, operation, arguments, WithDefaultHeaders(headers))
+	assert.False(t, result.IsError, resultText(t, result))
+
+	// a dry run shows them as they would be sent, with the ones named like credentials masked
+	preview := resultText(t, callTool(t, operation, arguments, WithDefaultHeaders(headers), WithDryRun()))
+	assert.Contains(t, preview, `"User-Agent": "axon-test"`)
+	assert.Contains(t, preview, `"X-Tenant": "acme"`)
+	assert.Contains(t, preview, `"X-Api-Key": "REDACTED"`)
+	assert.Contains(t, preview, `"X-Team": "search"`)
+}
@@ -0,0 +1,520 @@
+// Package jsonschema validates values against the JSON Schema documents axon generates for tools.
+// It covers the validation keywords of draft 2020-12 that the parser produces, formats and content keywords
+// are treated as annotations. unevaluatedItems and unevaluatedProperties aren't enforced.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Violation is a single way in which a value doesn't match its schema
+type Violation struct {
+	// Path locates the offending value, e.g. body.tags[0], and is empty for the value itself
+	Path    string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// Validate checks the value against the schema and returns every violation, in a stable order
+// $refs are resolved against the schema, so "#/$defs/Pet" points into its $defs
+func Validate(schema map[string]interface{}, value interface{}) []Violation {
+	v := &validator{root: schema}
+	v.validate(schema, value, "")
+	return v.violations
+}
+
+type validator struct {
+	root       map[string]interface{}
+	violations []Violation
+}
+
+func (v *validator) report(path string, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Checks the value against a subschema in isolation, used for the applicators that pick between schemas
+func (v *validator) matches(schema interface{}, value interface{}, path string) bool {
+	sub := &validator{root: v.root}
+	sub.validate(schema, value, path)
+	return len(sub.violations) == 0
+}
+
+func (v *validator) validate(schemaValue interface{}, value interface{}, path string) {
+	switch schema := schemaValue.(type) {
+	case bool:
+		if !schema {
+			v.report(path, "no value is allowed here")
+		}
+		return
+	case map[string]interface{}:
+		v.validateSchema(schema, value, path)
+	}
+}
+
+func (v *validator) validateSchema(schema map[string]interface{}, value interface{}, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := resolveRef(v.root, ref)
+		if err != nil {
+			v.report(path, "%v", err)
+		} else {
+			v.validate(target, value, path)
+		}
+	}
+
+	if types, ok := schema["type"]; ok {
+		allowed := asStrings(types)
+		if len(allowed) > 0 && !matchesAnyType(allowed, value) {
+			v.report(path, "expected %s, got %s", strings.Join(allowed, " or "), typeOf(value))
+			// the remaining keywords would only pile more errors on top of the wrong type
+			return
+		}
+	}
+
+	if constValue, ok := schema["const"]; ok && !equal(constValue, value) {
+		v.report(path, "must be %s", formatValue(constValue))
+	}
+	if enum, ok := schema["enum"]; ok {
+		options := asList(enum)
+		found := false
+		for _, option := range options {
+			if equal(option, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			formatted := make([]string, 0, len(options))
+			for _, option := range options {
+				formatted = append(formatted, formatValue(option))
+			}
+			v.report(path, "must be one of %s", strings.Join(formatted, ", "))
+		}
+	}
+
+	switch typed := value.(type) {
+	case string:
+		v.validateString(schema, typed, path)
+	case []interface{}:
+		v.validateArray(schema, typed, path)
+	case map[string]interface{}:
+		v.validateObject(schema, typed, path)
+	default:
+		if number, ok := toFloat(value); ok {
+			v.validateNumber(schema, number, path)
+		}
+	}
+
+	v.validateApplicators(schema, value, path)
+}
+
+func (v *validator) validateString(schema map[string]interface{}, value string, path string) {
+	length := utf8.RuneCountInString(value)
+	if minLength, ok := toFloat(schema["minLength"]); ok && float64(length) < minLength {
+		v.report(path, "must be at least %s characters long", formatNumber(minLength))
+	}
+	if maxLength, ok := toFloat(schema["maxLength"]); ok && float64(length) > maxLength {
+		v.report(path, "must be at most %s characters long", formatNumber(maxLength))
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := compilePattern(pattern)
+		if err == nil && !re.MatchString(value) {
+			v.report(path, "must match the pattern %s", pattern)
+		}
+	}
+}
+
+func (v *validator) validateNumber(schema map[string]interface{}, value float64, path string) {
+	if minimum, ok := toFloat(schema["minimum"]); ok && value < minimum {
+		v.report(path, "must be >= %s", formatNumber(minimum))
+	}
+	if maximum, ok := toFloat(schema["maximum"]); ok && value > maximum {
+		v.report(path, "must be <= %s", formatNumber(maximum))
+	}
+	if minimum, ok := toFloat(schema["exclusiveMinimum"]); ok && value <= minimum {
+		v.report(path, "must be > %s", formatNumber(minimum))
+	}
+	if maximum, ok := toFloat(schema["exclusiveMaximum"]); ok && value >= maximum {
+		v.report(path, "must be < %s", formatNumber(maximum))
+	}
+	if multipleOf, ok := toFloat(schema["multipleOf"]); ok && multipleOf > 0 {
+		quotient := value / multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.report(path, "must be a multiple of %s", formatNumber(multipleOf))
+		}
+	}
+}
+
+func (v *validator) validateArray(schema map[string]interface{}, value []interface{}, path string) {
+	if minItems, ok := toFloat(schema["minItems"]); ok && float64(len(value)) < minItems {
+		v.report(path, "must have at least %s items", formatNumber(minItems))
+	}
+	if maxItems, ok := toFloat(schema["maxItems"]); ok && float64(len(value)) > maxItems {
+		v.report(path, "must have at most %s items", formatNumber(maxItems))
+	}
+	if unique, ok := schema["uniqueItems"].(bool); ok && unique {
+	outer:
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if equal(value[i], value[j]) {
+					v.report(path, "items must be unique, items %d and %d are equal", i, j)
+					break outer
+				}
+			}
+		}
+	}
+
+	prefixItems := asList(schema["prefixItems"])
+	for i, item := range value {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if i < len(prefixItems) {
+			v.validate(prefixItems[i], item, itemPath)
+		} else if items, ok := schema["items"]; ok {
+			v.validate(items, item, itemPath)
+		}
+	}
+
+	if contains, ok := schema["contains"]; ok {
+		matched := 0
+		for i, item := range value {
+			if v.matches(contains, item, fmt.Sprintf("%s[%d]", path, i)) {
+				matched++
+			}
+		}
+		minContains := 1.0
+		if minimum, ok := toFloat(schema["minContains"]); ok {
+			minContains = minimum
+		}
+		if float64(matched) < minContains {
+			v.report(path, "must contain at least %s matching items, but contains %d", formatNumber(minContains), matched)
+		}
+		if maxContains, ok := toFloat(schema["maxContains"]); ok && float64(matched) > maxContains {
+			v.report(path, "must contain at most %s matching items, but contains %d", formatNumber(maxContains), matched)
+		}
+	}
+}
+
+func (v *validator) validateObject(schema map[string]interface{}, value map[string]interface{}, path string) {
+	for _, name := range asStrings(schema["required"]) {
+		if _, ok := value[name]; !ok {
+			v.report(joinPath(path, name), "is required")
+		}
+	}
+	if minProperties, ok := toFloat(schema["minProperties"]); ok && float64(len(value)) < minProperties {
+		v.report(path, "must have at least %s properties", formatNumber(minProperties))
+	}
+	if maxProperties, ok := toFloat(schema["maxProperties"]); ok && float64(len(value)) > maxProperties {
+		v.report(path, "must have at most %s properties", formatNumber(maxProperties))
+	}
+
+	dependentRequired, _ := schema["dependentRequired"].(map[string]interface{})
+	for _, name := range sortedKeys(dependentRequired) {
+		if _, ok := value[name]; !ok {
+			continue
+		}
+		for _, dependency := range asStrings(dependentRequired[name]) {
+			if _, ok := value[dependency]; !ok {
+				v.report(joinPath(path, dependency), "is required when %s is set", name)
+			}
+		}
+	}
+	dependentSchemas, _ := schema["dependentSchemas"].(map[string]interface{})
+	for _, name := range sortedKeys(dependentSchemas) {
+		if _, ok := value[name]; ok {
+			v.validate(dependentSchemas[name], value, path)
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	patternProperties, _ := schema["patternProperties"].(map[string]interface{})
+	propertyNames, hasPropertyNames := schema["propertyNames"]
+	additional, hasAdditional := schema["additionalProperties"]
+
+	for _, name := range sortedKeys(value) {
+		propertyPath := joinPath(path, name)
+		if hasPropertyNames && !v.matches(propertyNames, name, propertyPath) {
+			v.report(propertyPath, "is not an allowed property name")
+		}
+
+		// a property is only additional when neither properties nor patternProperties cover it
+		covered := false
+		if property, ok := properties[name]; ok {
+			v.validate(property, value[name], propertyPath)
+			covered = true
+		}
+		for _, pattern := range sortedKeys(patternProperties) {
+			re, err := compilePattern(pattern)
+			if err == nil && re.MatchString(name) {
+				v.validate(patternProperties[pattern], value[name], propertyPath)
+				covered = true
+			}
+		}
+		if covered || !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok && !allowed {
+			v.report(propertyPath, "is not an allowed property")
+			continue
+		}
+		v.validate(additional, value[name], propertyPath)
+	}
+}
+
+func (v *validator) validateApplicators(schema map[string]interface{}, value interface{}, path string) {
+	for _, sub := range asList(schema["allOf"]) {
+		v.validate(sub, value, path)
+	}
+
+	if anyOf := asList(schema["anyOf"]); len(anyOf) > 0 {
+		matched := false
+		for _, sub := range anyOf {
+			if v.matches(sub, value, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.report(path, "must match at least one of the %d allowed schemas", len(anyOf))
+		}
+	}
+
+	if oneOf := asList(schema["oneOf"]); len(oneOf) > 0 {
+		matched := 0
+		for _, sub := range oneOf {
+			if v.matches(sub, value, path) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.report(path, "must match exactly one of the %d allowed schemas, but matches %d", len(oneOf), matched)
+		}
+	}
+
+	if not, ok := schema["not"]; ok && v.matches(not, value, path) {
+		v.report(path, "must not match the disallowed schema")
+	}
+
+	if condition, ok := schema["if"]; ok {
+		if v.matches(condition, value, path) {
+			if then, ok := schema["then"]; ok {
+				v.validate(then, value, path)
+			}
+		} else if otherwise, ok := schema["else"]; ok {
+			v.validate(otherwise, value, path)
+		}
+	}
+}
+
+// Resolves a reference to a location inside the root schema
+func resolveRef(root map[string]interface{}, ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("can't resolve the external reference %s", ref)
+	}
+
+	var current interface{} = root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("can't resolve the reference %s", ref)
+		}
+		if current, ok = object[token]; !ok {
+			return nil, fmt.Errorf("can't resolve the reference %s", ref)
+		}
+	}
+	return current, nil
+}
+
+func matchesAnyType(types []string, value interface{}) bool {
+	for _, t := range types {
+		if matchesType(t, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesType(t string, value interface{}) bool {
+	switch t {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		number, ok := toFloat(value)
+		return ok && number == math.Trunc(number)
+	}
+	// unknown types place no constraint on the value
+	return true
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if number, ok := toFloat(value); ok {
+		if number == math.Trunc(number) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// Converts any Go number, as produced by decoding JSON or by the spec loaders, to a float64
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case int, int8, int16, int32, int64:
+		return float64(reflect.ValueOf(n).Int()), true
+	case uint, uint8, uint16, uint32, uint64:
+		return float64(reflect.ValueOf(n).Uint()), true
+	}
+	return 0, false
+}
+
+// Compares two JSON values, numbers are equal when their values are regardless of their Go type
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(value interface{}) interface{} {
+	if number, ok := toFloat(value); ok {
+		return number
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for i, item := range v {
+			normalized[i] = normalize(item)
+		}
+		return normalized
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			normalized[key] = normalize(item)
+		}
+		return normalized
+	}
+
+	// typed slices like []string come straight from the converter
+	if reflected := reflect.ValueOf(value); reflected.Kind() == reflect.Slice {
+		return normalize(asList(value))
+	}
+	return value
+}
+
+// Reads a list keyword, which may be any kind of slice depending on where the schema came from
+func asList(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	reflected := reflect.ValueOf(value)
+	if !reflected.IsValid() || reflected.Kind() != reflect.Slice {
+		return nil
+	}
+	list := make([]interface{}, reflected.Len())
+	for i := range list {
+		list[i] = reflected.Index(i).Interface()
+	}
+	return list
+}
+
+// Reads a keyword that is either a single string or a list of them
+func asStrings(value interface{}) []string {
+	if s, ok := value.(string); ok {
+		return []string{s}
+	}
+	var result []string
+	for _, item := range asList(value) {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func formatValue(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+var patterns sync.Map
+
+// Compiles a pattern once, patterns that Go can't compile are ignored rather than failing every call
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := patterns.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
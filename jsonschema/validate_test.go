@@ -0,0 +1,124 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, content string) interface{} {
+	t.Helper()
+	var value interface{}
+	require.NoError(t, json.Unmarshal([]byte(content), &value))
+	return value
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		value    string
+		expected []string
+	}{
+		{"valid object", `{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}`, `{"id": 3}`, nil},
+		{"missing required", `{"type": "object", "required": ["id", "name"]}`, `{"id": 3}`, []string{"name: is required"}},
+		{"wrong type", `{"type": "integer"}`, `"3"`, []string{"expected integer, got string"}},
+		{"integer with a fraction", `{"type": "integer"}`, `3.5`, []string{"expected integer, got number"}},
+		{"nullable", `{"type": ["string", "null"]}`, `null`, nil},
+		{"enum", `{"enum": ["available", "sold"]}`, `"lost"`, []string{`must be one of "available", "sold"`}},
+		{"const", `{"const": "GET"}`, `"POST"`, []string{`must be "GET"`}},
+		{"string limits", `{"type": "string", "minLength": 2, "maxLength": 3, "pattern": "^[a-z]+$"}`, `"A"`, []string{
+			"must be at least 2 characters long",
+			"must match the pattern ^[a-z]+$",
+		}},
+		{"number limits", `{"minimum": 1, "exclusiveMaximum": 10, "multipleOf": 2}`, `10`, []string{"must be < 10"}},
+		{"multipleOf", `{"multipleOf": 0.1}`, `0.3`, nil},
+		{"array", `{"type": "array", "items": {"type": "string"}, "maxItems": 2, "uniqueItems": true}`, `["a", 1, "a"]`, []string{
+			"must have at most 2 items",
+			"items must be unique, items 0 and 2 are equal",
+			"[1]: expected string, got integer",
+		}},
+		{"additional properties", `{"properties": {"a": {}}, "additionalProperties": false}`, `{"a": 1, "b": 2}`, []string{"b: is not an allowed property"}},
+		{"additional properties schema", `{"additionalProperties": {"type": "string"}}`, `{"a": "x", "b": 2}`, []string{"b: expected string, got integer"}},
+		{"allOf", `{"allOf": [{"required": ["a"]}, {"required": ["b"]}]}`, `{}`, []string{"a: is required", "b: is required"}},
+		{"anyOf", `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `true`, []string{"must match at least one of the 2 allowed schemas"}},
+		{"oneOf", `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, []string{"must match exactly one of the 2 allowed schemas, but matches 2"}},
+		{"not", `{"not": {"type": "string"}}`, `"a"`, []string{"must not match the disallowed schema"}},
+		{"false schema", `{"properties": {"a": false}}`, `{"a": 1}`, []string{"a: no value is allowed here"}},
+		{"pattern properties", `{"properties": {"id": {}}, "patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": false}`, `{"id": 1, "x-tenant": "acme", "x-trace": 2, "other": 3}`, []string{
+			"other: is not an allowed property",
+			"x-trace: expected string, got integer",
+		}},
+		{"property names", `{"propertyNames": {"pattern": "^[a-z]+$"}}`, `{"ok": 1, "Bad": 2}`, []string{"Bad: is not an allowed property name"}},
+		{"dependent required", `{"dependentRequired": {"card": ["billingAddress"]}}`, `{"card": "4242"}`, []string{"billingAddress: is required when card is set"}},
+		{"dependent required satisfied", `{"dependentRequired": {"card": ["billingAddress"]}}`, `{"name": "Rex"}`, nil},
+		{"dependent schemas", `{"dependentSchemas": {"card": {"required": ["cvc"]}}}`, `{"card": "4242"}`, []string{"cvc: is required"}},
+		{"contains", `{"contains": {"type": "string"}, "maxContains": 1}`, `["a", "b", 1]`, []string{"must contain at most 1 matching items, but contains 2"}},
+		{"contains nothing", `{"contains": {"type": "string"}}`, `[1, 2]`, []string{"must contain at least 1 matching items, but contains 0"}},
+		{"if then else", `{"if": {"properties": {"kind": {"const": "dog"}}}, "then": {"required": ["breed"]}, "else": {"required": ["species"]}}`, `{"kind": "cat"}`, []string{"species: is required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := decode(t, tt.schema).(map[string]interface{})
+			var messages []string
+			for _, violation := range Validate(schema, decode(t, tt.value)) {
+				messages = append(messages, violation.String())
+			}
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+func TestValidate_NestedBody(t *testing.T) {
+	schema := decode(t, `{
+  "type": "object",
+  "properties": {
+    "petId": {"type": "integer"},
+    "body": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {
+        "name": {"type": "string"},
+        "owner": {"$ref": "#/$defs/Person"}
+      }
+    }
+  },
+  "required": ["petId", "body"],
+  "$defs": {
+    "Person": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {"name": {"type": "string"}, "friends": {"type": "array", "items": {"$ref": "#/$defs/Person"}}}
+    }
+  }
+}`).(map[string]interface{})
+
+	violations := Validate(schema, decode(t, `{"body": {"owner": {"name": "Ann", "friends": [{"name": 7}, {}]}}}`))
+	assert.Equal(t, []Violation{
+		{"petId", "is required"},
+		{"body.name", "is required"},
+		{"body.owner.friends[0].name", "expected string, got integer"},
+		{"body.owner.friends[1].name", "is required"},
+	}, violations)
+}
+
+func TestValidate_GoTypes(t *testing.T) {
+	// schemas built in Go use typed slices and integers instead of what encoding/json produces
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []string{"tags"},
+		"properties": map[string]interface{}{
+			"tags":  map[string]interface{}{"type": []string{"array"}, "maxItems": uint64(1)},
+			"limit": map[string]interface{}{"enum": []interface{}{10, 20}},
+		},
+	}
+
+	assert.Empty(t, Validate(schema, map[string]interface{}{"tags": []interface{}{"a"}, "limit": float64(10)}))
+	assert.Equal(t, []Violation{
+		{"limit", "must be one of 10, 20"},
+		{"tags", "must have at most 1 items"},
+	}, Validate(schema, map[string]interface{}{"tags": []interface{}{"a", "b"}, "limit": float64(15)}))
+}
@@ -87,8 +87,9 @@ type PromptMessage struct {
 // benefit of the LLM and/or the user.
 type EmbeddedResource struct {
 	Annotated
-	Type     string           `json:"type"`
-	Resource ResourceContents `json:"resource"`
+	Type string `json:"type"`
+	// Resource is either TextResourceContents or BlobResourceContents
+	Resource interface{} `json:"resource"`
 }
 
 // PromptListChangedNotification is an optional notification from the server
@@ -71,6 +71,8 @@ type ToolInputSchema struct {
 	Type       string                 `json:"type"`
 	Properties map[string]interface{} `json:"properties,omitempty"`
 	Required   []string               `json:"required,omitempty"`
+	// Shared schema definitions that properties can point at with "$ref": "#/$defs/<name>".
+	Defs map[string]interface{} `json:"$defs,omitempty"`
 }
 
 // ToolOption is a function that configures a Tool.
@@ -314,3 +316,29 @@ func WithString(name string, opts ...PropertyOption) ToolOption {
 		t.InputSchema.Properties[name] = schema
 	}
 }
+
+// WithObject adds an object property to the tool schema.
+// It accepts property options to configure the object property's behavior and constraints.
+func WithObject(name string, opts ...PropertyOption) ToolOption {
+	return func(t *Tool) {
+		schema := map[string]interface{}{
+			"type": "object",
+		}
+
+		for _, opt := range opts {
+			opt(schema)
+		}
+
+		// Remove required from property schema and add to InputSchema.required
+		if required, ok := schema["required"].(bool); ok && required {
+			delete(schema, "required")
+			if t.InputSchema.Required == nil {
+				t.InputSchema.Required = []string{name}
+			} else {
+				t.InputSchema.Required = append(t.InputSchema.Required, name)
+			}
+		}
+
+		t.InputSchema.Properties[name] = schema
+	}
+}
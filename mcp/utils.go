@@ -204,8 +204,8 @@ func NewImageContent(data, mimeType string) ImageContent {
 	}
 }
 
-// Helper function to create a new EmbeddedResource
-func NewEmbeddedResource(resource ResourceContents) EmbeddedResource {
+// Helper function to create a new EmbeddedResource from TextResourceContents or BlobResourceContents
+func NewEmbeddedResource(resource interface{}) EmbeddedResource {
 	return EmbeddedResource{
 		Type:     "resource",
 		Resource: resource,
@@ -254,10 +254,10 @@ func NewToolResultImage(text, imageData, mimeType string) *CallToolResult {
 	}
 }
 
-// NewToolResultResource creates a new CallToolResult with an embedded resource
+// NewToolResultResource creates a new CallToolResult with an embedded resource, either TextResourceContents or BlobResourceContents
 func NewToolResultResource(
 	text string,
-	resource ResourceContents,
+	resource interface{},
 ) *CallToolResult {
 	return &CallToolResult{
 		Content: []interface{}{
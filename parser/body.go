@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// How the body argument of a tool is encoded into the request
+const (
+	BodyEncodingJSON      = "json"
+	BodyEncodingForm      = "form"
+	BodyEncodingMultipart = "multipart"
+	BodyEncodingText      = "text"
+	BodyEncodingBinary    = "binary"
+)
+
+// Body encodings in the order they are preferred when an operation accepts more than one media type
+var bodyEncodingPreference = []string{
+	BodyEncodingJSON,
+	BodyEncodingMultipart,
+	BodyEncodingForm,
+	BodyEncodingText,
+	BodyEncodingBinary,
+}
+
+// RequestBody describes how the body argument is sent
+type RequestBody struct {
+	// MediaType is sent as the Content-Type, e.g. application/json
+	MediaType string
+	Encoding  string
+	// FileFields are the multipart fields that take files
+	FileFields []string
+	// FixedFields are form fields sent whatever the arguments say, they aren't shown to the model (x-mcp-default)
+	FixedFields map[string]interface{}
+}
+
+// Classifies a media type by how a body of that type is encoded
+func bodyEncoding(mediaType string) string {
+	parsed, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		parsed = strings.ToLower(mediaType)
+	}
+
+	switch {
+	case parsed == "application/json" || strings.HasSuffix(parsed, "+json"):
+		return BodyEncodingJSON
+	case parsed == "multipart/form-data":
+		return BodyEncodingMultipart
+	case parsed == "application/x-www-form-urlencoded":
+		return BodyEncodingForm
+	case strings.HasPrefix(parsed, "text/") || parsed == "application/xml" || strings.HasSuffix(parsed, "+xml"):
+		return BodyEncodingText
+	default:
+		return BodyEncodingBinary
+	}
+}
+
+// Picks the media type the body is sent as from the ones an operation accepts
+func selectMediaType(mediaTypes []string) string {
+	for _, encoding := range bodyEncodingPreference {
+		for _, mediaType := range mediaTypes {
+			if bodyEncoding(mediaType) == encoding {
+				return mediaType
+			}
+		}
+	}
+	return ""
+}
+
+// Schema of an argument that takes a file, either as base64 content or as a path on the machine axon runs on
+func fileSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "object",
+		"description": "A file, given either as base64 encoded content or as the path of a file in an allowed upload directory",
+		"properties": map[string]interface{}{
+			"content": map[string]interface{}{
+				"type":            "string",
+				"contentEncoding": "base64",
+			},
+			"path": map[string]interface{}{
+				"type": "string",
+			},
+			"filename": map[string]interface{}{
+				"type": "string",
+			},
+			"contentType": map[string]interface{}{
+				"type": "string",
+			},
+		},
+	}
+}
+
+// Schema of a body that isn't structured, text is written by the model and anything else is uploaded as a file
+func rawBodySchema(encoding string, mediaType string) map[string]interface{} {
+	if encoding == BodyEncodingText {
+		return map[string]interface{}{
+			"type":             "string",
+			"contentMediaType": mediaType,
+		}
+	}
+	return fileSchema()
+}
+
+// Adds the media type to the description of a body schema so the model knows what it is writing
+func describeBody(schema map[string]interface{}, mediaType string) {
+	if description, ok := schema["description"].(string); ok && description != "" {
+		schema["description"] = description + " (sent as " + mediaType + ")"
+		return
+	}
+	schema["description"] = "Request body, sent as " + mediaType
+}
+
+// Converts the request body of an OpenAPI operation into the schema of the body argument
+func convertOpenAPIRequestBody(converter *schemaConverter, requestBody *openapi3.RequestBody) (map[string]interface{}, *RequestBody) {
+	mediaType := selectMediaType(sortedKeys(requestBody.Content))
+	if mediaType == "" {
+		return nil, nil
+	}
+	content := requestBody.Content[mediaType]
+	body := &RequestBody{
+		MediaType: mediaType,
+		Encoding:  bodyEncoding(mediaType),
+	}
+
+	var schema map[string]interface{}
+	switch body.Encoding {
+	case BodyEncodingText, BodyEncodingBinary:
+		schema = rawBodySchema(body.Encoding, mediaType)
+	case BodyEncodingMultipart:
+		schema = converter.convertRef(content.Schema)
+		body.FileFields = replaceFileProperties(schema, content.Schema)
+	default:
+		schema = converter.convertRef(content.Schema)
+	}
+	describeBody(schema, mediaType)
+
+	return schema, body
+}
+
+// Swaps the binary properties of a multipart schema for file arguments and returns their names
+func replaceFileProperties(schema map[string]interface{}, schemaRef *openapi3.SchemaRef) []string {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return nil
+	}
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var fileFields []string
+	for _, name := range sortedKeys(schemaRef.Value.Properties) {
+		property := schemaRef.Value.Properties[name]
+		if property == nil || property.Value == nil {
+			continue
+		}
+
+		if isBinarySchema(property.Value) {
+			properties[name] = withDescription(fileSchema(), property.Value.Description)
+			fileFields = append(fileFields, name)
+		} else if property.Value.Items != nil && property.Value.Items.Value != nil && isBinarySchema(property.Value.Items.Value) {
+			properties[name] = withDescription(map[string]interface{}{
+				"type":  "array",
+				"items": fileSchema(),
+			}, property.Value.Description)
+			fileFields = append(fileFields, name)
+		}
+	}
+	return fileFields
+}
+
+// Reports whether a schema describes raw file content
+func isBinarySchema(schema *openapi3.Schema) bool {
+	return schema.Type.Is("string") && schema.Format == "binary"
+}
+
+func withDescription(schema map[string]interface{}, description string) map[string]interface{} {
+	if description != "" {
+		schema["description"] = description
+	}
+	return schema
+}
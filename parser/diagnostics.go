@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity of a diagnostic
+type Severity string
+
+const (
+	// SeverityError is a problem that makes the spec invalid, strict loading refuses the spec
+	SeverityError Severity = "error"
+	// SeverityWarning is a problem the tools were adjusted for
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic reports a problem found while converting a spec
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	// Pointer is the JSON pointer of the part of the spec the diagnostic is about, e.g. /paths/~1pets/get
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s %s: %s", d.Severity, d.Pointer, d.Message)
+}
+
+// Diagnostics collects the diagnostics of a conversion
+type Diagnostics []Diagnostic
+
+// HasErrors reports whether any of the diagnostics is an error
+func (d Diagnostics) HasErrors() bool {
+	for _, diagnostic := range d {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// WithDiagnostics appends every diagnostic found while converting the spec to diagnostics
+func WithDiagnostics(diagnostics *Diagnostics) ParseOption {
+	return func(c *parseConfig) {
+		c.diagnostics = diagnostics
+	}
+}
+
+// WithLenient converts every operation it can instead of refusing a spec that doesn't validate
+// The problems are reported as error diagnostics, operations that can't be converted at all are left out
+func WithLenient() ParseOption {
+	return func(c *parseConfig) {
+		c.lenient = true
+	}
+}
+
+func (c *parseConfig) warn(pointer string, format string, args ...interface{}) {
+	c.report(SeverityWarning, pointer, fmt.Sprintf(format, args...))
+}
+
+func (c *parseConfig) fail(pointer string, format string, args ...interface{}) {
+	c.report(SeverityError, pointer, fmt.Sprintf(format, args...))
+}
+
+func (c *parseConfig) report(severity Severity, pointer string, message string) {
+	if c.diagnostics == nil {
+		return
+	}
+	diagnostic := Diagnostic{
+		Severity: severity,
+		Pointer:  pointer,
+		Message:  message,
+	}
+	// e.g. a problem with the top level security is found again for every operation
+	for _, reported := range *c.diagnostics {
+		if reported == diagnostic {
+			return
+		}
+	}
+	*c.diagnostics = append(*c.diagnostics, diagnostic)
+}
+
+// Builds the JSON pointer of an operation
+func operationPointer(path string, method string) string {
+	return "/paths/" + escapePointer(path) + "/" + strings.ToLower(method)
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,231 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// specDocument is a spec, or a document a spec references, parsed once whether it is JSON or YAML
+type specDocument struct {
+	// content is the document as JSON, which is what kin-openapi and go-openapi are handed
+	content json.RawMessage
+	// swagger and openapi are the version fields as written, e.g. 2.0 or 3.0.3
+	swagger string
+	openapi string
+}
+
+// Spec formats, told apart by the version field a document has
+const (
+	specFormatSwagger = "swagger"
+	specFormatOpenAPI = "openapi"
+)
+
+// format tells Swagger documents from OpenAPI ones by their version field and returns the version it holds
+// A document with both fields is ambiguous and refused
+func (d *specDocument) format() (string, string, error) {
+	switch {
+	case d.swagger != "" && d.openapi != "":
+		return "", "", fmt.Errorf("spec has both a swagger and an openapi field, it must have exactly one")
+	case d.swagger != "":
+		return specFormatSwagger, d.swagger, nil
+	case d.openapi != "":
+		return specFormatOpenAPI, d.openapi, nil
+	}
+	return "", "", fmt.Errorf("no version information found in spec")
+}
+
+// version returns the swagger or openapi version of the document
+func (d *specDocument) version() (string, error) {
+	_, version, err := d.format()
+	return version, err
+}
+
+// Parses a JSON or YAML document, JSON is kept as it is and YAML is converted to JSON
+func parseSpecDocument(content []byte) (*specDocument, error) {
+	if json.Valid(content) {
+		return parseJSONDocument(content)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("spec is neither JSON nor valid YAML: %w", err)
+	}
+
+	value, err := yamlValue(&root)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &specDocument{}
+	// versions are taken from the YAML text, unquoted they would be numbers like 2 instead of 2.0
+	if mapping := yamlRootMapping(&root); mapping != nil {
+		object := value.(map[string]interface{})
+		document.swagger = yamlMappingScalar(mapping, "swagger")
+		document.openapi = yamlMappingScalar(mapping, "openapi")
+		if document.swagger != "" {
+			object["swagger"] = document.swagger
+		}
+		if document.openapi != "" {
+			object["openapi"] = document.openapi
+		}
+		if info, ok := object["info"].(map[string]interface{}); ok {
+			if version := yamlMappingScalar(yamlMappingValue(mapping, "info"), "version"); version != "" {
+				info["version"] = version
+			}
+		}
+	}
+
+	document.content, err = json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert YAML spec to JSON: %w", err)
+	}
+	return document, nil
+}
+
+func parseJSONDocument(content []byte) (*specDocument, error) {
+	document := &specDocument{content: content}
+
+	// only objects have version fields, a referenced document may be anything
+	if trimmed := bytes.TrimSpace(content); len(trimmed) == 0 || trimmed[0] != '{' {
+		return document, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+	document.swagger = jsonScalar(fields["swagger"])
+	document.openapi = jsonScalar(fields["openapi"])
+	return document, nil
+}
+
+// Reads a version field that should be a string but may have been written as a number
+func jsonScalar(raw json.RawMessage) string {
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value
+	}
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number.String()
+	}
+	return ""
+}
+
+func yamlRootMapping(root *yaml.Node) *yaml.Node {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	return node
+}
+
+// Returns the value of a key in a mapping, nil when the key is missing
+func yamlMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Returns the text of a scalar in a mapping, empty when the key is missing or holds something else
+func yamlMappingScalar(mapping *yaml.Node, key string) string {
+	value := yamlMappingValue(mapping, key)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return value.Value
+}
+
+// Converts a YAML node to the value encoding/json would produce for the same document
+// Keys are always strings, e.g. response codes, and timestamps stay the text they were written as
+func yamlValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlValue(node.Content[0])
+
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+
+	case yaml.MappingNode:
+		object := make(map[string]interface{}, len(node.Content)/2)
+		var merges []*yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.ShortTag() == "!!merge" {
+				merges = append(merges, value)
+				continue
+			}
+			converted, err := yamlValue(value)
+			if err != nil {
+				return nil, err
+			}
+			object[key.Value] = converted
+		}
+		// keys merged in with << never override the ones the mapping sets itself
+		for _, merge := range merges {
+			sources := []*yaml.Node{merge}
+			if merge.Kind == yaml.SequenceNode {
+				sources = merge.Content
+			}
+			for _, source := range sources {
+				converted, err := yamlValue(source)
+				if err != nil {
+					return nil, err
+				}
+				merged, ok := converted.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("line %d: only mappings can be merged", source.Line)
+				}
+				for key, value := range merged {
+					if _, ok := object[key]; !ok {
+						object[key] = value
+					}
+				}
+			}
+		}
+		return object, nil
+
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			converted, err := yamlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, converted)
+		}
+		return list, nil
+
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			return nil, nil
+		case "!!bool", "!!int", "!!float":
+			var value interface{}
+			if err := node.Decode(&value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+			return value, nil
+		default:
+			return node.Value, nil
+		}
+	}
+
+	return nil, fmt.Errorf("line %d: unsupported YAML %s", node.Line, strings.TrimPrefix(node.ShortTag(), "!!"))
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSpecRouter_Formats(t *testing.T) {
+	tests := []struct {
+		file    string
+		version string
+		tools   []string
+	}{
+		{"petstore.json", "3.0.3", []string{"listPets"}},
+		{"petstore.yaml", "3.0.3", []string{"listOwnerPets", "listPets"}},
+		{"stocks.yml", "2.0", []string{"getQuotes"}},
+		{"stocks-unquoted.yaml", "2.0", []string{"getSymbols"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			specPath := filepath.Join("testdata", tt.file)
+
+			version, err := DetectSpecVersion(specPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, version)
+
+			tools, err := ParseSpecRouter(specPath)
+			require.NoError(t, err)
+			var names []string
+			for _, tool := range tools {
+				names = append(names, tool.Name)
+			}
+			assert.Equal(t, tt.tools, names)
+		})
+	}
+}
+
+func TestParseSpecDocument_YAML(t *testing.T) {
+	document, err := parseSpecDocument([]byte(`
+openapi: 3.1.0
+info:
+  title: pets
+  version: 2024-01-01
+x-base: &base
+  type: string
+  description: base
+schema:
+  <<: *base
+  description: merged
+responses:
+  200: {description: ok}
+flags: [yes, true, null, 1.5]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "3.1.0", document.openapi)
+	assert.JSONEq(t, `{
+  "openapi": "3.1.0",
+  "info": {"title": "pets", "version": "2024-01-01"},
+  "x-base": {"type": "string", "description": "base"},
+  "schema": {"type": "string", "description": "merged"},
+  "responses": {"200": {"description": "ok"}},
+  "flags": ["yes", true, null, 1.5]
+}`, string(document.content))
+
+	_, err = parseSpecDocument([]byte("openapi: [3.0"))
+	assert.ErrorContains(t, err, "neither JSON nor valid YAML")
+
+	document, err = parseSpecDocument([]byte(`{"info": {"title": "pets"}}`))
+	require.NoError(t, err)
+	_, err = document.version()
+	assert.ErrorContains(t, err, "no version information found in spec")
+}
+
+func TestParseSpecRouter_VersionField(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected string
+	}{
+		{"openapi 2.0", `{"openapi": "2.0", "info": {"title": "pets", "version": "1"}, "paths": {}}`, "unsupported specification version: openapi 2.0"},
+		{"swagger 3.0", `{"swagger": "3.0.3", "info": {"title": "pets", "version": "1"}, "paths": {}}`, "unsupported specification version: swagger 3.0.3"},
+		{"both", `{"swagger": "2.0", "openapi": "3.0.3", "info": {"title": "pets", "version": "1"}, "paths": {}}`, "it must have exactly one"},
+		{"neither", `{"info": {"title": "pets", "version": "1"}, "paths": {}}`, "no version information found in spec"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSpecRouter(writeSpec(t, t.TempDir(), "spec.json", tt.spec))
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ExtensionConfirm marks an operation as one that needs (true) or doesn't need (false) confirmation before it runs
+const ExtensionConfirm = "x-axon-confirm"
+
+// Vendor extensions that let API owners tune how their operations look to the model
+const (
+	// ExtensionName replaces the operationId as the name of the tool
+	ExtensionName = "x-mcp-name"
+	// ExtensionDescription replaces the description of an operation or parameter
+	ExtensionDescription = "x-mcp-description"
+	// ExtensionHidden leaves an operation out, or hides an optional parameter from the model
+	ExtensionHidden = "x-mcp-hidden"
+	// ExtensionDefault pins the value of a parameter, which is then hidden from the model
+	ExtensionDefault = "x-mcp-default"
+	// ExtensionExamples adds example arguments to an operation or example values to a parameter
+	ExtensionExamples = "x-mcp-examples"
+)
+
+// parameterExtensions are the x-mcp-* extensions of a parameter
+type parameterExtensions struct {
+	description string
+	hidden      bool
+	fixed       interface{}
+	examples    []interface{}
+}
+
+func readParameterExtensions(extensions map[string]interface{}) parameterExtensions {
+	return parameterExtensions{
+		description: extensionString(extensions, ExtensionDescription),
+		hidden:      extensionFlag(extensions, ExtensionHidden),
+		fixed:       extensions[ExtensionDefault],
+		examples:    extensionList(extensions, ExtensionExamples),
+	}
+}
+
+// Applies the extensions to the schema of the parameter
+func (e parameterExtensions) apply(schema map[string]interface{}) {
+	if e.description != "" {
+		schema["description"] = e.description
+	}
+	if len(e.examples) > 0 {
+		schema["examples"] = e.examples
+	}
+}
+
+// visible reports whether the model gets to see the parameter
+// A hidden parameter that is required stays visible unless its value is pinned, calls couldn't succeed otherwise
+func (e parameterExtensions) visible(required bool) bool {
+	if e.fixed != nil {
+		return false
+	}
+	return !e.hidden || required
+}
+
+// Warns about a required parameter that is hidden without a value to send in its place, it is shown anyway
+func warnHiddenRequired(config *parseConfig, pointer string, name string, required bool, extensions map[string]interface{}) {
+	if !required {
+		return
+	}
+	if e := readParameterExtensions(extensions); e.hidden && e.fixed == nil {
+		config.warn(pointer, "parameter %s is required, it stays visible since %s has no %s", name, ExtensionHidden, ExtensionDefault)
+	}
+}
+
+// Applies the x-mcp-description and x-mcp-examples extensions of an operation to its description
+func describeOperation(description string, extensions map[string]interface{}) string {
+	if override := extensionString(extensions, ExtensionDescription); override != "" {
+		description = override
+	}
+
+	examples := extensionList(extensions, ExtensionExamples)
+	if len(examples) == 0 {
+		return description
+	}
+
+	var text strings.Builder
+	text.WriteString(description)
+	text.WriteString("\n\nExample arguments:")
+	for _, example := range examples {
+		encoded, err := json.Marshal(example)
+		if err != nil {
+			continue
+		}
+		text.WriteString("\n- ")
+		text.Write(encoded)
+	}
+	return text.String()
+}
+
+// Reads a boolean vendor extension, nil when the operation doesn't set it to a boolean
+func extensionBool(extensions map[string]interface{}, name string) *bool {
+	value, ok := extensions[name].(bool)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func extensionFlag(extensions map[string]interface{}, name string) bool {
+	value := extensionBool(extensions, name)
+	return value != nil && *value
+}
+
+func extensionString(extensions map[string]interface{}, name string) string {
+	value, _ := extensions[name].(string)
+	return value
+}
+
+// Reads an extension that holds a list, a single value is taken as a list of one
+func extensionList(extensions map[string]interface{}, name string) []interface{} {
+	switch value := extensions[name].(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return value
+	default:
+		return []interface{}{value}
+	}
+}
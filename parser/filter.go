@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Filter selects which operations of a spec are turned into tools
+// An operation is kept when it matches every include rule that is set and none of the exclude rules
+// Operations without an operationId are matched by their synthesized name, e.g. get_pets_by_petId
+type Filter struct {
+	// IncludeOperations keeps only the operations with these operationIds when it isn't empty
+	IncludeOperations []string
+	// ExcludeOperations drops the operations with these operationIds
+	ExcludeOperations []string
+	// IncludeOperationPattern keeps only the operations whose operationId matches
+	IncludeOperationPattern *regexp.Regexp
+	// ExcludeOperationPattern drops the operations whose operationId matches
+	ExcludeOperationPattern *regexp.Regexp
+	// IncludeTags keeps only the operations with at least one of these tags
+	IncludeTags []string
+	// ExcludeTags drops the operations with any of these tags
+	ExcludeTags []string
+	// IncludePaths keeps only the operations whose path matches one of these globs
+	// A * matches within a single path segment and ** matches any number of segments, e.g. /pets/* or /admin/**
+	IncludePaths []string
+	// ExcludePaths drops the operations whose path matches one of these globs
+	ExcludePaths []string
+	// IncludeMethods keeps only the operations with these HTTP methods
+	IncludeMethods []string
+	// ExcludeMethods drops the operations with these HTTP methods
+	ExcludeMethods []string
+	// ExcludeDeprecated drops the operations marked deprecated
+	ExcludeDeprecated bool
+}
+
+// filterCandidate is what a filter looks at to decide on an operation
+type filterCandidate struct {
+	id         string
+	method     string
+	path       string
+	tags       []string
+	deprecated bool
+}
+
+// Returns why the filter drops an operation, or an empty string when it keeps it
+func (f Filter) rejects(op filterCandidate) string {
+	switch {
+	case len(f.IncludeOperations) > 0 && !containsString(f.IncludeOperations, op.id):
+		return fmt.Sprintf("%s isn't one of the included operations", op.id)
+	case f.IncludeOperationPattern != nil && !f.IncludeOperationPattern.MatchString(op.id):
+		return fmt.Sprintf("%s doesn't match the included operation pattern", op.id)
+	case len(f.IncludeTags) > 0 && !containsAny(f.IncludeTags, op.tags):
+		return "none of its tags are included"
+	case len(f.IncludePaths) > 0 && !matchesAnyPath(f.IncludePaths, op.path):
+		return "its path doesn't match the included paths"
+	case len(f.IncludeMethods) > 0 && !containsFold(f.IncludeMethods, op.method):
+		return "its method isn't included"
+	case containsString(f.ExcludeOperations, op.id):
+		return fmt.Sprintf("%s is excluded", op.id)
+	case f.ExcludeOperationPattern != nil && f.ExcludeOperationPattern.MatchString(op.id):
+		return fmt.Sprintf("%s matches the excluded operation pattern", op.id)
+	case containsAny(f.ExcludeTags, op.tags):
+		return "one of its tags is excluded"
+	case matchesAnyPath(f.ExcludePaths, op.path):
+		return "its path is excluded"
+	case containsFold(f.ExcludeMethods, op.method):
+		return "its method is excluded"
+	case f.ExcludeDeprecated && op.deprecated:
+		return "it is deprecated"
+	}
+	return ""
+}
+
+func containsAny(values []string, candidates []string) bool {
+	for _, candidate := range candidates {
+		if containsString(values, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyPath(globs []string, operationPath string) bool {
+	for _, glob := range globs {
+		if matchPathGlob(glob, operationPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches a path against a glob where * stays within a segment and ** spans any number of segments
+func matchPathGlob(glob string, operationPath string) bool {
+	return matchSegments(strings.Split(strings.Trim(glob, "/"), "/"), strings.Split(strings.Trim(operationPath, "/"), "/"))
+}
+
+func matchSegments(glob []string, segments []string) bool {
+	if len(glob) == 0 {
+		return len(segments) == 0
+	}
+	if glob[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(glob[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, err := path.Match(glob[0], segments[0]); err != nil || !matched {
+		return false
+	}
+	return matchSegments(glob[1:], segments[1:])
+}
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPathGlob(t *testing.T) {
+	tests := []struct {
+		glob     string
+		path     string
+		expected bool
+	}{
+		{"/pets", "/pets", true},
+		{"/pets/*", "/pets/{petId}", true},
+		{"/pets/*", "/pets/{petId}/photos", false},
+		{"/pets/**", "/pets", true},
+		{"/pets/**", "/pets/{petId}/photos", true},
+		{"/**/photos", "/pets/{petId}/photos", true},
+		{"/admin/**", "/pets", false},
+		{"/store/order*", "/store/orders", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchPathGlob(tt.glob, tt.path))
+		})
+	}
+}
+
+func TestConvertOpenAPIToMCPTools_Filter(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "paths": {
+    "/pets": {
+      "get": {"operationId": "listPets", "tags": ["pets"], "responses": {"200": {"description": "ok"}}},
+      "post": {"operationId": "createPet", "tags": ["pets"], "responses": {"200": {"description": "ok"}}}
+    },
+    "/pets/{petId}": {
+      "get": {"operationId": "getPet", "tags": ["pets"], "responses": {"200": {"description": "ok"}}},
+      "delete": {"operationId": "deletePet", "tags": ["pets", "admin"], "responses": {"200": {"description": "ok"}}}
+    },
+    "/pets/find": {
+      "get": {"operationId": "findPetsByTags", "tags": ["pets"], "deprecated": true, "responses": {"200": {"description": "ok"}}}
+    },
+    "/store/orders": {
+      "get": {"operationId": "listOrders", "tags": ["store"], "responses": {"200": {"description": "ok"}}}
+    }
+  }
+}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{
+			name:     "tags",
+			filter:   Filter{IncludeTags: []string{"pets"}, ExcludeTags: []string{"admin"}},
+			expected: []string{"listPets", "createPet", "findPetsByTags", "getPet"},
+		},
+		{
+			name:     "paths",
+			filter:   Filter{IncludePaths: []string{"/pets/**"}, ExcludePaths: []string{"/pets/find"}},
+			expected: []string{"listPets", "createPet", "getPet", "deletePet"},
+		},
+		{
+			name:     "methods",
+			filter:   Filter{IncludeMethods: []string{"get"}},
+			expected: []string{"listPets", "findPetsByTags", "getPet", "listOrders"},
+		},
+		{
+			name:     "operationId patterns",
+			filter:   Filter{IncludeOperationPattern: regexp.MustCompile(`^(list|get)`), ExcludeOperationPattern: regexp.MustCompile(`Orders$`)},
+			expected: []string{"listPets", "getPet"},
+		},
+		{
+			name:     "deprecated",
+			filter:   Filter{ExcludeDeprecated: true, ExcludeMethods: []string{"DELETE", "POST"}},
+			expected: []string{"listPets", "getPet", "listOrders"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tools, err := ConvertOpenAPIToMCPTools(doc, WithFilter(tt.filter))
+			require.NoError(t, err)
+
+			var names []string
+			for _, tool := range tools {
+				names = append(names, tool.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestConvertOpenAPIToMCPTools_FilterDiagnostics(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "paths": {
+    "/pets": {
+      "get": {"operationId": "listPets", "tags": ["pets"], "responses": {"200": {"description": "ok"}}},
+      "post": {"operationId": "createPet", "tags": ["pets"], "x-mcp-hidden": true, "responses": {"200": {"description": "ok"}}}
+    },
+    "/pets/{petId}": {
+      "delete": {"operationId": "deletePet", "tags": ["pets", "admin"], "responses": {"200": {"description": "ok"}}}
+    },
+    "/store/orders": {
+      "get": {"tags": ["store"], "responses": {"200": {"description": "ok"}}}
+    }
+  }
+}`))
+	require.NoError(t, err)
+
+	var diagnostics Diagnostics
+	tools, err := ConvertOpenAPIToMCPTools(doc, WithDiagnostics(&diagnostics), WithFilter(Filter{
+		IncludeOperationPattern: regexp.MustCompile(`Pet`),
+		ExcludeTags:             []string{"admin"},
+	}))
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "listPets", tools[0].Name)
+
+	assert.Equal(t, Diagnostics{
+		{Severity: SeverityWarning, Pointer: "/paths/~1pets/post", Message: "left out, the operation is marked x-mcp-hidden"},
+		{Severity: SeverityWarning, Pointer: "/paths/~1pets~1{petId}/delete", Message: "left out by the filter, one of its tags is excluded"},
+		{Severity: SeverityWarning, Pointer: "/paths/~1store~1orders/get", Message: "left out by the filter, get_store_orders doesn't match the included operation pattern"},
+	}, diagnostics)
+}
@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	openapierrors "github.com/go-openapi/errors"
+	"github.com/go-openapi/spec"
+)
+
+// validatable is a part of an OpenAPI spec kin-openapi can validate on its own
+type validatable interface {
+	Validate(ctx context.Context, opts ...openapi3.ValidationOption) error
+}
+
+// Reports every problem of an OpenAPI spec that failed validation as an error diagnostic
+// kin-openapi stops at the first problem, so the parts of the spec are validated one by one to find all of them
+func reportOpenAPIProblems(ctx context.Context, doc *openapi3.T, config *parseConfig, opts []openapi3.ValidationOption, validationErr error) {
+	ctx = openapi3.WithValidationOptions(ctx, opts...)
+
+	problems := 0
+	fail := func(pointer string, err error) {
+		problems++
+		config.fail(pointer, "%v", err)
+	}
+	validate := func(pointer string, part validatable) {
+		if err := part.Validate(ctx); err != nil {
+			fail(pointer, err)
+		}
+	}
+
+	if doc.Components != nil {
+		validateComponents(ctx, "schemas", doc.Components.Schemas, fail)
+		validateComponents(ctx, "parameters", doc.Components.Parameters, fail)
+		validateComponents(ctx, "requestBodies", doc.Components.RequestBodies, fail)
+		validateComponents(ctx, "responses", doc.Components.Responses, fail)
+		validateComponents(ctx, "headers", doc.Components.Headers, fail)
+		validateComponents(ctx, "securitySchemes", doc.Components.SecuritySchemes, fail)
+		validateComponents(ctx, "examples", doc.Components.Examples, fail)
+		validateComponents(ctx, "links", doc.Components.Links, fail)
+		validateComponents(ctx, "callbacks", doc.Components.Callbacks, fail)
+	}
+
+	if doc.Info == nil {
+		fail("/info", errors.New("must be an object"))
+	} else {
+		validate("/info", doc.Info)
+	}
+
+	if doc.Paths != nil {
+		paths := doc.Paths.Map()
+		for _, path := range sortedKeys(paths) {
+			pathItem := paths[path]
+			if pathItem == nil || len(pathItem.Operations()) == 0 {
+				validate("/paths/"+escapePointer(path), openapi3.NewPaths(openapi3.WithPath(path, pathItem)))
+				continue
+			}
+
+			// each operation is checked with the path level parameters, which tells which operations miss path parameters
+			for _, method := range httpMethods {
+				operation := pathItem.GetOperation(method)
+				if operation == nil {
+					continue
+				}
+				single := &openapi3.PathItem{Parameters: pathItem.Parameters}
+				single.SetOperation(method, operation)
+				validate(operationPointer(path, method), openapi3.NewPaths(openapi3.WithPath(path, single)))
+			}
+		}
+	}
+
+	if doc.Security != nil {
+		validate("/security", doc.Security)
+	}
+	if doc.Servers != nil {
+		validate("/servers", doc.Servers)
+	}
+	if doc.Tags != nil {
+		validate("/tags", doc.Tags)
+	}
+	if doc.ExternalDocs != nil {
+		validate("/externalDocs", doc.ExternalDocs)
+	}
+
+	// a problem the parts don't show on their own, e.g. two operations with the same operationId
+	if problems == 0 {
+		fail("", validationErr)
+	}
+}
+
+// Validates the components of one section, e.g. schemas
+func validateComponents[V validatable](ctx context.Context, section string, components map[string]V, fail func(string, error)) {
+	for _, name := range sortedKeys(components) {
+		pointer := "/components/" + section + "/" + escapePointer(name)
+		if err := openapi3.ValidateIdentifier(name); err != nil {
+			fail(pointer, err)
+			continue
+		}
+		if err := components[name].Validate(ctx); err != nil {
+			fail(pointer, err)
+		}
+	}
+}
+
+// quotedName matches the place go-openapi quotes at the start of some of its messages, e.g. "paths./pets.get.parameters" must validate...
+var quotedName = regexp.MustCompile(`^"([a-zA-Z]+\.[^"]+)"`)
+
+// Reports the errors go-openapi found in a Swagger spec as error diagnostics
+func reportSwaggerProblems(doc *spec.Swagger, config *parseConfig, problems []error) {
+	for _, problem := range problems {
+		var composite *openapierrors.CompositeError
+		if errors.As(problem, &composite) {
+			reportSwaggerProblems(doc, config, composite.Errors)
+			continue
+		}
+
+		pointer := ""
+		var validation *openapierrors.Validation
+		if errors.As(problem, &validation) {
+			pointer = swaggerPointer(doc, validation.Name)
+		} else if match := quotedName.FindStringSubmatch(problem.Error()); match != nil {
+			pointer = swaggerPointer(doc, match[1])
+		}
+		config.fail(pointer, "%v", problem)
+	}
+}
+
+// Converts the dotted name go-openapi gives the place of a problem, e.g. paths./pets.get.parameters.in, to a JSON pointer
+// Paths and definition names may contain dots themselves, so they are matched against the keys of the spec
+func swaggerPointer(doc *spec.Swagger, name string) string {
+	if name == "" {
+		return ""
+	}
+
+	var keys []string
+	section, rest, ok := strings.Cut(name, ".")
+	switch section {
+	case "paths":
+		if doc.Paths != nil {
+			keys = sortedKeys(doc.Paths.Paths)
+		}
+	case "definitions":
+		keys = sortedKeys(doc.Definitions)
+	case "parameters":
+		keys = sortedKeys(doc.Parameters)
+	case "responses":
+		keys = sortedKeys(doc.Responses)
+	case "securityDefinitions":
+		keys = sortedKeys(doc.SecurityDefinitions)
+	}
+
+	pointer := "/" + escapePointer(section)
+	if !ok {
+		return pointer
+	}
+
+	// the longest key wins, /pets.json is a better match for paths./pets.json.get than /pets
+	matched := ""
+	for _, key := range keys {
+		if (rest == key || strings.HasPrefix(rest, key+".")) && len(key) > len(matched) {
+			matched = key
+		}
+	}
+	if matched != "" {
+		pointer += "/" + escapePointer(matched)
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, matched), ".")
+		if rest == "" {
+			return pointer
+		}
+	}
+
+	for _, token := range strings.Split(rest, ".") {
+		pointer += "/" + escapePointer(token)
+	}
+	return pointer
+}
@@ -0,0 +1,188 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const invalidOpenAPISpec = `
+openapi: 3.0.3
+info:
+  title: Pets
+servers:
+  - url: https://pets.example.com
+paths:
+  /pets/{petId}:
+    get:
+      operationId: getPet
+      responses:
+        "200": {description: A pet}
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200": {description: The pets}
+  /stores:
+    get:
+      operationId: listStores
+      servers:
+        - url: https://{region}.example.com
+      responses:
+        "200": {description: The stores}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: strin}
+`
+
+const invalidSwaggerSpec = `
+swagger: "2.0"
+info:
+  title: Pets
+host: pets.example.com
+paths:
+  /pets/{petId}:
+    get:
+      operationId: getPet
+      responses:
+        200: {description: A pet}
+  /pets.json:
+    get:
+      operationId: listPets
+      parameters:
+        - {name: limit, in: nowhere, type: integer}
+      responses:
+        200: {description: The pets}
+`
+
+func TestParseSpecRouter_StrictRefusesInvalidSpecs(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := ParseSpecRouter(writeSpec(t, dir, "openapi.yaml", invalidOpenAPISpec))
+	assert.ErrorContains(t, err, "invalid OpenAPI spec")
+
+	_, err = ParseSpecRouter(writeSpec(t, dir, "swagger.yaml", invalidSwaggerSpec))
+	assert.ErrorContains(t, err, "invalid Swagger spec")
+}
+
+func TestParseSpecRouter_LenientOpenAPI(t *testing.T) {
+	var diagnostics Diagnostics
+	tools, err := ParseSpecRouter(writeSpec(t, t.TempDir(), "openapi.yaml", invalidOpenAPISpec), WithLenient(), WithDiagnostics(&diagnostics))
+	require.NoError(t, err)
+
+	// the server of listStores can't be resolved, the other operations are converted despite their problems
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	assert.ElementsMatch(t, []string{"getPet", "listPets"}, names)
+
+	assert.True(t, diagnostics.HasErrors())
+	pointers := make(map[string]Severity)
+	for _, diagnostic := range diagnostics {
+		pointers[diagnostic.Pointer] = diagnostic.Severity
+	}
+	assert.Equal(t, SeverityError, pointers["/info"])
+	assert.Equal(t, SeverityError, pointers["/components/schemas/Pet"])
+	assert.Equal(t, SeverityError, pointers["/paths/~1pets~1{petId}/get"])
+	assert.Equal(t, SeverityError, pointers["/paths/~1stores/get"])
+	assert.NotContains(t, pointers, "/paths/~1pets/get")
+}
+
+func TestParseSpecRouter_LenientSwagger(t *testing.T) {
+	var diagnostics Diagnostics
+	tools, err := ParseSpecRouter(writeSpec(t, t.TempDir(), "swagger.yaml", invalidSwaggerSpec), WithLenient(), WithDiagnostics(&diagnostics))
+	require.NoError(t, err)
+	assert.Len(t, tools, 2)
+
+	pointers := make(map[string]bool)
+	for _, diagnostic := range diagnostics {
+		assert.Equal(t, SeverityError, diagnostic.Severity)
+		pointers[diagnostic.Pointer] = true
+	}
+	assert.True(t, pointers["/info/version"])
+	assert.True(t, pointers["/paths/~1pets.json/get/parameters/in"])
+}
+
+func TestSwaggerPointer(t *testing.T) {
+	doc := &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Paths: &spec.Paths{Paths: map[string]spec.PathItem{"/pets": {}, "/pets.json": {}, "/pets/{petId}": {}}},
+	}}
+
+	assert.Equal(t, "/paths/~1pets.json/get/parameters", swaggerPointer(doc, "paths./pets.json.get.parameters"))
+	assert.Equal(t, "/paths/~1pets~1{petId}", swaggerPointer(doc, "paths./pets/{petId}"))
+	assert.Equal(t, "/info/version", swaggerPointer(doc, "info.version"))
+	assert.Equal(t, "/definitions/Pet/type", swaggerPointer(doc, "definitions.Pet.type"))
+	assert.Equal(t, "", swaggerPointer(doc, ""))
+}
+
+func TestParseSpecRouter_UndefinedSecurityScheme(t *testing.T) {
+	dir := t.TempDir()
+	openAPI := writeSpec(t, dir, "openapi.yaml", `
+openapi: 3.0.3
+info: {title: Pets, version: "1.0"}
+servers:
+  - url: https://pets.example.com
+security:
+  - petstore_auth: []
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200": {description: The pets}
+    post:
+      operationId: createPet
+      security:
+        - api_key: []
+        - petstore_auth: []
+      responses:
+        "201": {description: Created}
+components:
+  securitySchemes:
+    api_key: {type: apiKey, in: header, name: X-API-Key}
+`)
+	swagger := writeSpec(t, dir, "swagger.yaml", `
+swagger: "2.0"
+info: {title: Pets, version: "1.0"}
+host: pets.example.com
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      security:
+        - petstore_auth: []
+      responses:
+        200: {description: The pets}
+`)
+
+	_, err := ParseSpecRouter(openAPI)
+	assert.ErrorContains(t, err, `security scheme "petstore_auth" is not defined in components.securitySchemes`)
+
+	var diagnostics Diagnostics
+	tools, err := ParseSpecRouter(openAPI, WithLenient(), WithDiagnostics(&diagnostics))
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+	for _, tool := range tools {
+		for _, requirement := range tool.Operation.Security {
+			assert.NotEmpty(t, requirement, "an undefined scheme doesn't leave an empty requirement behind")
+		}
+	}
+	assert.Equal(t, Diagnostics{
+		{Severity: SeverityError, Pointer: "/security/0", Message: `security scheme "petstore_auth" is not defined in components.securitySchemes, requests that need it can't be authorized`},
+		{Severity: SeverityError, Pointer: "/paths/~1pets/post/security/1", Message: `security scheme "petstore_auth" is not defined in components.securitySchemes, requests that need it can't be authorized`},
+	}, diagnostics)
+
+	diagnostics = nil
+	tools, err = ParseSpecRouter(swagger, WithLenient(), WithDiagnostics(&diagnostics))
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, []SecurityRequirement{{{Name: "petstore_auth"}}}, tools[0].Operation.Security)
+	assert.Contains(t, diagnostics, Diagnostic{Severity: SeverityError, Pointer: "/paths/~1pets/get/security/0", Message: `security scheme "petstore_auth" is not defined in securityDefinitions, requests that need it can't be authorized`})
+}
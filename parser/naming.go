@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxToolNameLength is the longest tool name MCP clients accept
+const maxToolNameLength = 64
+
+var invalidToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// namedOperation is what the tool name of an operation is derived from
+type namedOperation struct {
+	operationID string
+	method      string
+	path        string
+}
+
+// Picks a unique, valid tool name for every operation of a spec
+// Operations with an operationId are named first so a synthesized name never takes the name the spec asked for
+func assignToolNames(config *parseConfig, operations []namedOperation) []string {
+	names := make([]string, len(operations))
+	taken := make(map[string]bool)
+
+	assign := func(i int) {
+		op := operations[i]
+		pointer := operationPointer(op.path, op.method)
+
+		base := op.operationID
+		if base == "" {
+			base = synthesizeOperationName(op.method, op.path)
+			config.warn(pointer, "operation has no operationId, the tool is named %s", config.toolName(base))
+		}
+
+		name := sanitizeToolName(config.toolName(base))
+		if op.operationID != "" && name != config.toolName(op.operationID) {
+			config.warn(pointer, "operationId %q isn't a valid tool name, the tool is named %s", op.operationID, name)
+		}
+
+		unique := name
+		for n := 2; taken[unique]; n++ {
+			suffix := fmt.Sprintf("_%d", n)
+			unique = truncateToolName(name, maxToolNameLength-len(suffix)) + suffix
+		}
+		if unique != name {
+			config.warn(pointer, "tool name %s is already taken, the tool is named %s", name, unique)
+		}
+
+		taken[unique] = true
+		names[i] = unique
+	}
+
+	for i, op := range operations {
+		if op.operationID != "" {
+			assign(i)
+		}
+	}
+	for i, op := range operations {
+		if op.operationID == "" {
+			assign(i)
+		}
+	}
+
+	return names
+}
+
+// Derives an operation name from its method and path, e.g. GET /pets/{petId} becomes get_pets_by_petId
+func synthesizeOperationName(method string, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			parts = append(parts, "by", segment[1:len(segment)-1])
+			continue
+		}
+		parts = append(parts, segment)
+	}
+	return strings.Join(parts, "_")
+}
+
+// Replaces every run of characters that aren't allowed in a tool name with _ and cuts the name to the maximum length
+func sanitizeToolName(name string) string {
+	return truncateToolName(invalidToolNameChars.ReplaceAllString(name, "_"), maxToolNameLength)
+}
+
+func truncateToolName(name string, length int) string {
+	if len(name) <= length {
+		return name
+	}
+	return name[:length]
+}
+
+// Names an operation converted on its own, the path may be a full URL
+func operationName(operationID string, method string, path string) string {
+	if operationID != "" {
+		return sanitizeToolName(operationID)
+	}
+	if parsed, err := url.Parse(path); err == nil {
+		path = parsed.Path
+	}
+	return sanitizeToolName(synthesizeOperationName(method, path))
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSynthesizeOperationName(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{"GET", "/pets", "get_pets"},
+		{"GET", "/pets/{petId}", "get_pets_by_petId"},
+		{"DELETE", "/users/{id}/repos/{repo}", "delete_users_by_id_repos_by_repo"},
+		{"POST", "/", "post"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, synthesizeOperationName(tt.method, tt.path))
+		})
+	}
+}
+
+func TestSanitizeToolName(t *testing.T) {
+	assert.Equal(t, "pets_list_all-pets", sanitizeToolName("pets.list all-pets"))
+	assert.Equal(t, "reports_id_", sanitizeToolName("reports/{id}"))
+	assert.Len(t, sanitizeToolName(strings.Repeat("a", 100)), maxToolNameLength)
+}
+
+func TestAssignToolNames(t *testing.T) {
+	var diagnostics Diagnostics
+	config := newParseConfig([]ParseOption{WithDiagnostics(&diagnostics)})
+
+	names := assignToolNames(config, []namedOperation{
+		{"", "GET", "/pets"},
+		{"get_pets", "GET", "/animals"},
+		{"", "GET", "/pets/{petId}"},
+		{"list.pets", "GET", "/v2/pets"},
+		{"", "GET", "/pets/"},
+	})
+
+	// the explicit operationId keeps its name, the synthesized one moves aside
+	assert.Equal(t, []string{"get_pets_2", "get_pets", "get_pets_by_petId", "list_pets", "get_pets_3"}, names)
+	assert.Equal(t, Diagnostics{
+		{SeverityWarning, "/paths/~1v2~1pets/get", `operationId "list.pets" isn't a valid tool name, the tool is named list_pets`},
+		{SeverityWarning, "/paths/~1pets/get", "operation has no operationId, the tool is named get_pets"},
+		{SeverityWarning, "/paths/~1pets/get", "tool name get_pets is already taken, the tool is named get_pets_2"},
+		{SeverityWarning, "/paths/~1pets~1{petId}/get", "operation has no operationId, the tool is named get_pets_by_petId"},
+		{SeverityWarning, "/paths/~1pets~1/get", "operation has no operationId, the tool is named get_pets"},
+		{SeverityWarning, "/paths/~1pets~1/get", "tool name get_pets is already taken, the tool is named get_pets_3"},
+	}, diagnostics)
+}
+
+func TestAssignToolNames_PrefixAndLength(t *testing.T) {
+	config := newParseConfig([]ParseOption{WithToolPrefix("petstore")})
+
+	long := strings.Repeat("a", 70)
+	names := assignToolNames(config, []namedOperation{
+		{long, "GET", "/a"},
+		{long + "b", "GET", "/b"},
+		{"", "GET", "/pets"},
+	})
+
+	assert.Equal(t, "petstore_"+strings.Repeat("a", 55), names[0])
+	assert.Equal(t, "petstore_"+strings.Repeat("a", 53)+"_2", names[1])
+	assert.Equal(t, "petstore_get_pets", names[2])
+}
+
+func TestConvertOpenAPIToMCPTools_WithoutOperationID(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "servers": [{"url": "https://pets.example.com"}],
+  "paths": {
+    "/pets/{petId}": {
+      "get": {
+        "parameters": [{"name": "petId", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"200": {"description": "ok"}}
+      },
+      "head": {"responses": {"200": {"description": "ok"}}}
+    }
+  }
+}`))
+	require.NoError(t, err)
+
+	var diagnostics Diagnostics
+	tools, err := ConvertOpenAPIToMCPTools(doc, WithDiagnostics(&diagnostics))
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+	assert.Equal(t, "get_pets_by_petId", tools[0].Name)
+	assert.Equal(t, "get_pets_by_petId", tools[0].Operation.ID)
+	assert.Equal(t, "https://pets.example.com/pets/{petId}", tools[0].Operation.URL)
+	assert.Equal(t, "head_pets_by_petId", tools[1].Name)
+	assert.Len(t, diagnostics, 2)
+}
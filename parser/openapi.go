@@ -9,88 +9,112 @@ import (
 )
 
 // Converts an OpenAPI spec to an array of MCP Tools that a Host can recognize
-func ConvertOpenAPIToMCPTools(spec *openapi3.T) ([]mcp.Tool, error) {
-	var tools []mcp.Tool
-
-	// Extract the base url
-	// TODO: update this to handle multiple servers
-	baseUrl := spec.Servers[0].URL
+func ConvertOpenAPIToMCPTools(spec *openapi3.T, opts ...ParseOption) ([]Tool, error) {
+	var tools []Tool
+	config := newParseConfig(opts)
+
+	type openAPIOperation struct {
+		id        string
+		path      string
+		method    string
+		pathItem  *openapi3.PathItem
+		operation *openapi3.Operation
+	}
 
-	// Iterate through all paths
-	for path, method := range spec.Paths.Map() {
-		if method == nil {
+	// Collect the operations in a stable order so synthesized names don't change between runs
+	var operations []openAPIOperation
+	var named []namedOperation
+	paths := spec.Paths.Map()
+	for _, path := range sortedKeys(paths) {
+		pathItem := paths[path]
+		if pathItem == nil {
 			continue
 		}
 
-		// construct the entire path
-		fullPath := baseUrl + path
-
-		if method.Get != nil {
-			tool, err := ConvertOperationToMCPTool(method.Get, "GET", fullPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert GET operation for %s: %w", fullPath, err)
+		for _, method := range httpMethods {
+			operation := pathItem.GetOperation(method)
+			if operation == nil {
+				continue
 			}
-			if tool != nil {
-				tools = append(tools, *tool)
+			pointer := operationPointer(path, method)
+			if extensionFlag(operation.Extensions, ExtensionHidden) {
+				config.warn(pointer, "left out, the operation is marked %s", ExtensionHidden)
+				continue
 			}
-		}
 
-		if method.Post != nil {
-			tool, err := ConvertOperationToMCPTool(method.Post, "POST", fullPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert POST operation for %s: %w", fullPath, err)
+			name := operation.OperationID
+			if name == "" {
+				name = synthesizeOperationName(method, path)
 			}
-			if tool != nil {
-				tools = append(tools, *tool)
+			if reason := config.filter.rejects(filterCandidate{name, method, path, operation.Tags, operation.Deprecated}); reason != "" {
+				config.warn(pointer, "left out by the filter, %s", reason)
+				continue
 			}
+
+			operations = append(operations, openAPIOperation{name, path, method, pathItem, operation})
+			named = append(named, namedOperation{openAPIOperationName(operation), method, path})
 		}
+	}
 
-		if method.Put != nil {
-			tool, err := ConvertOperationToMCPTool(method.Put, "PUT", fullPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert PUT operation for %s: %w", fullPath, err)
-			}
-			if tool != nil {
-				tools = append(tools, *tool)
+	names := assignToolNames(config, named)
+	for i, op := range operations {
+		pointer := operationPointer(op.path, op.method)
+		baseURL, err := resolveOperationServer(config, spec.Servers, op.pathItem.Servers, op.operation)
+		if err != nil {
+			if config.lenient {
+				config.fail(pointer, "left out, failed to resolve its server: %v", err)
+				continue
 			}
+			return nil, fmt.Errorf("failed to resolve server for %s %s: %w", op.method, op.path, err)
 		}
 
-		if method.Delete != nil {
-			tool, err := ConvertOperationToMCPTool(method.Delete, "DELETE", fullPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert DELETE operation for %s: %w", fullPath, err)
-			}
-			if tool != nil {
-				tools = append(tools, *tool)
+		// construct the entire path
+		fullPath := baseURL + op.path
+
+		// parameters declared on the path apply to every operation unless the operation overrides them
+		operation := *op.operation
+		operation.Parameters = mergeOpenAPIParameters(op.pathItem.Parameters, op.operation.Parameters)
+
+		for _, param := range operation.Parameters {
+			if param.Value != nil {
+				warnHiddenRequired(config, pointer, param.Value.Name, param.Value.Required, param.Value.Extensions)
 			}
 		}
 
-		if method.Patch != nil {
-			tool, err := ConvertOperationToMCPTool(method.Patch, "PATCH", fullPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert PATCH operation for %s: %w", fullPath, err)
-			}
-			if tool != nil {
-				tools = append(tools, *tool)
+		tool, err := ConvertOperationToMCPTool(&operation, op.method, fullPath)
+		if err != nil {
+			if config.lenient {
+				config.fail(pointer, "left out, failed to convert it: %v", err)
+				continue
 			}
+			return nil, fmt.Errorf("failed to convert %s operation for %s: %w", op.method, fullPath, err)
+		}
+		tool.Name = names[i]
+		tool.Operation.ID = op.id
+		security, err := openAPISecurity(spec, op.operation, config, pointer)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op.method, fullPath, err)
 		}
+		tool.Operation.Security = security
+		hideCredentialParameters(tool)
+		tools = append(tools, *tool)
 	}
 
 	return tools, nil
 }
 
+// Converts a single operation to a tool
+// Operations without an operationId are named after their method and path
 func ConvertOperationToMCPTool(
 	operation *openapi3.Operation,
 	method string,
 	path string,
-) (*mcp.Tool, error) {
-	if operation.OperationID == "" {
-		return nil, nil
-	}
-
+) (*Tool, error) {
 	// Create properties map for the tool schema
 	properties := make(map[string]interface{})
 	required := []string{}
+	var parameters []Parameter
+	converter := newSchemaConverter(directionRequest)
 
 	// Add endpoint information
 	properties["endpoint"] = map[string]interface{}{
@@ -102,27 +126,53 @@ func ConvertOperationToMCPTool(
 		"const": method,
 	}
 
-	// Handle path parameters
+	locations := make(parameterLocations)
+	for _, param := range operation.Parameters {
+		if param.Value != nil {
+			locations.add(param.Value.Name, param.Value.In)
+		}
+	}
+
+	// Handle path, query, header and cookie parameters
 	for _, param := range operation.Parameters {
 		if param.Value == nil {
 			continue
 		}
 
-		schema := ConvertSchemaToMap(param.Value.Schema.Value)
+		parameter, err := newOpenAPIParameter(param.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter %s: %w", param.Value.Name, err)
+		}
+		extensions := readParameterExtensions(param.Value.Extensions)
+		parameter.Fixed = extensions.fixed
+		argument := locations.argument(param.Value.Name, param.Value.In)
+		if argument != param.Value.Name {
+			parameter.Argument = argument
+		}
+		parameters = append(parameters, parameter)
+
+		if !extensions.visible(param.Value.Required) {
+			continue
+		}
+
+		schema := converter.convertRef(parameterSchema(param.Value))
 		if param.Value.Description != "" {
 			schema["description"] = param.Value.Description
 		}
+		extensions.apply(schema)
 
-		properties[param.Value.Name] = schema
+		properties[argument] = schema
 		if param.Value.Required {
-			required = append(required, param.Value.Name)
+			required = append(required, argument)
 		}
 	}
 
 	// Handle request body
+	var body *RequestBody
 	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
-		if jsonContent := operation.RequestBody.Value.Content.Get("application/json"); jsonContent != nil {
-			bodySchema := ConvertSchemaToMap(jsonContent.Schema.Value)
+		var bodySchema map[string]interface{}
+		bodySchema, body = convertOpenAPIRequestBody(converter, operation.RequestBody.Value)
+		if body != nil {
 			properties["body"] = bodySchema
 			if operation.RequestBody.Value.Required {
 				required = append(required, "body")
@@ -138,83 +188,83 @@ func ConvertOperationToMCPTool(
 	if description == "" {
 		description = fmt.Sprintf("%s %s", method, path)
 	}
+	description = describeOperation(description, operation.Extensions)
+
+	inputSchema := mcp.ToolInputSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   required,
+	}
+	if len(converter.defs) > 0 {
+		inputSchema.Defs = converter.defs
+	}
 
-	return &mcp.Tool{
-		Name:        operation.OperationID,
-		Description: description,
-		InputSchema: mcp.ToolInputSchema{
-			Type:       "object",
-			Properties: properties,
-			Required:   required,
+	return &Tool{
+		Tool: mcp.Tool{
+			Name:        operationName(openAPIOperationName(operation), method, path),
+			Description: description,
+			InputSchema: inputSchema,
+		},
+		Operation: Operation{
+			Method:     method,
+			URL:        path,
+			Parameters: parameters,
+			Body:       body,
+			Responses:  convertOpenAPIResponses(operation.Responses),
+			Confirm:    extensionBool(operation.Extensions, ExtensionConfirm),
 		},
 	}, nil
 }
 
-// Gets the schema type for the Open API spec
-func GetSchemaType(types *openapi3.Types) string {
-	if types == nil || len(*types) == 0 {
-		return "object" // default to object type
+// The name a tool is derived from, x-mcp-name wins over the operationId
+func openAPIOperationName(operation *openapi3.Operation) string {
+	if name := extensionString(operation.Extensions, ExtensionName); name != "" {
+		return name
 	}
-	return (*types)[0] // take the first type
+	return operation.OperationID
 }
 
-func ConvertSchemaToMap(schema *openapi3.Schema) map[string]interface{} {
-	if schema == nil {
-		return map[string]interface{}{"type": "object"}
-	}
-
-	result := make(map[string]interface{})
-
-	// Handle basic properties
-	// schema.Type is a string slice
-	if len(GetSchemaType(schema.Type)) > 0 {
-		result["type"] = "object"
-	}
-	if schema.Format != "" {
-		result["format"] = schema.Format
-	}
-	if schema.Description != "" {
-		result["description"] = schema.Description
+// Merges path level parameters with the ones of an operation
+// An operation parameter with the same name and location replaces the path level one
+func mergeOpenAPIParameters(pathParameters openapi3.Parameters, operationParameters openapi3.Parameters) openapi3.Parameters {
+	if len(pathParameters) == 0 {
+		return operationParameters
 	}
 
-	// Handle array type
-	if schema.Items != nil && schema.Items.Value != nil {
-		result["items"] = ConvertSchemaToMap(schema.Items.Value)
+	overridden := make(map[string]bool)
+	for _, param := range operationParameters {
+		if param.Value != nil {
+			overridden[param.Value.In+":"+param.Value.Name] = true
+		}
 	}
 
-	// Handle object properties
-	if schema.Properties != nil {
-		props := make(map[string]interface{})
-		for name, prop := range schema.Properties {
-			props[name] = ConvertSchemaToMap(prop.Value)
-		}
-		result["properties"] = props
-		if len(schema.Required) > 0 {
-			result["required"] = schema.Required
+	merged := make(openapi3.Parameters, 0, len(pathParameters)+len(operationParameters))
+	for _, param := range pathParameters {
+		if param.Value != nil && overridden[param.Value.In+":"+param.Value.Name] {
+			continue
 		}
+		merged = append(merged, param)
 	}
+	return append(merged, operationParameters...)
+}
 
-	// Handle constraints
-	if schema.Min != nil {
-		result["minimum"] = *schema.Min
-	}
-	if schema.Max != nil {
-		result["maximum"] = *schema.Max
+// Gets the schema of a parameter, which is either set directly or through a single content entry
+func parameterSchema(param *openapi3.Parameter) *openapi3.SchemaRef {
+	if param.Schema != nil {
+		return param.Schema
 	}
-	if schema.MinLength != 0 {
-		result["minLength"] = schema.MinLength
-	}
-	if schema.MaxLength != nil {
-		result["maxLength"] = *schema.MaxLength
-	}
-	if schema.Pattern != "" {
-		result["pattern"] = schema.Pattern
-	}
-	if len(schema.Enum) > 0 {
-		result["enum"] = schema.Enum
+	for _, mediaType := range param.Content {
+		return mediaType.Schema
 	}
+	return nil
+}
 
-	return result
+// Gets the schema type for the Open API spec
+func GetSchemaType(types *openapi3.Types) string {
+	if types == nil || len(*types) == 0 {
+		return "object" // default to object type
+	}
+	return (*types)[0] // take the first type
 }
 
 // Checks if the arg passed in is a URL
@@ -223,24 +273,62 @@ func IsURL(url string) bool {
 }
 
 // Load in a OpenApi spec. Will return an error if the spec is not valid.
-func LoadOpenApiSpec(specPath string) (*openapi3.T, error) {
+// With WithLenient an invalid spec is returned anyway and every problem is reported as a diagnostic
+// An overlay passed with WithOverlay is applied once the spec is validated
+func LoadOpenApiSpec(specPath string, opts ...ParseOption) (*openapi3.T, error) {
+	config := newParseConfig(opts)
+	fetcher, err := config.specFetcher(specPath)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := fetcher.readRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
+	}
+	openAPI31 := isOpenAPI31(root.openapi)
+
 	loader := openapi3.NewLoader()
-	var doc *openapi3.T
-	var err error
+	// External $refs are read by the fetcher, which keeps them next to the spec
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+		document, err := fetcher.read(location)
+		if err != nil {
+			return nil, err
+		}
+		if openAPI31 {
+			return normalizeOpenAPI31(document.content, document == root, config)
+		}
+		return document.content, nil
+	}
 
-	if IsURL(specPath) {
-		doc, err = loader.LoadFromURI(&url.URL{Path: specPath})
+	var doc *openapi3.T
+	if fetcher.remote() {
+		doc, err = loader.LoadFromURI(fetcher.root)
 	} else {
-		doc, err = loader.LoadFromFile(specPath)
+		doc, err = loader.LoadFromFile(fetcher.root.Path)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
 	}
 
-	err = doc.Validate(loader.Context)
+	var validationOptions []openapi3.ValidationOption
+	if openAPI31 {
+		validationOptions = openAPI31ValidationOptions()
+	}
+	err = doc.Validate(loader.Context, validationOptions...)
 	if err != nil {
-		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+		if !config.lenient {
+			return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+		}
+		reportOpenAPIProblems(loader.Context, doc, config, validationOptions, err)
+	}
+
+	if config.overlay != nil {
+		if err := applyOpenAPIOverlay(doc, config.overlay); err != nil {
+			return nil, err
+		}
 	}
 
 	return doc, nil
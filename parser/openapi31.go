@@ -0,0 +1,267 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// kin-openapi only understands OpenAPI 3.0, so 3.1 documents are rewritten into the shape it can load
+// JSON Schema keywords 3.0 doesn't have are left in place, kin-openapi keeps them as extensions of the schema
+// and they are copied into the tool schemas as they are
+
+// jsonSchemaKeywords are the draft 2020-12 keywords OpenAPI 3.0 schemas don't have
+// They are passed through to tool schemas unchanged
+var jsonSchemaKeywords = []string{
+	"const",
+	"examples",
+	"prefixItems",
+	"contains",
+	"minContains",
+	"maxContains",
+	"unevaluatedItems",
+	"unevaluatedProperties",
+	"patternProperties",
+	"propertyNames",
+	"dependentRequired",
+	"dependentSchemas",
+	"if",
+	"then",
+	"else",
+	"contentMediaType",
+	"contentEncoding",
+	"contentSchema",
+}
+
+// openAPI31Fields are fields of 3.1 documents that 3.0 doesn't know, they are allowed through validation
+var openAPI31Fields = append([]string{
+	"$schema",
+	"$id",
+	"$anchor",
+	"$comment",
+	"$defs",
+	"$dynamicRef",
+	"$dynamicAnchor",
+	// info.summary and license.identifier
+	"summary",
+	"identifier",
+}, jsonSchemaKeywords...)
+
+// isOpenAPI31 reports whether the openapi version of a document is 3.1
+func isOpenAPI31(version string) bool {
+	return strings.HasPrefix(version, "3.1")
+}
+
+// openAPI31ValidationOptions lets the fields of 3.1 documents through validation
+func openAPI31ValidationOptions() []openapi3.ValidationOption {
+	return []openapi3.ValidationOption{openapi3.AllowExtraSiblingFields(openAPI31Fields...)}
+}
+
+// Rewrites an OpenAPI 3.1 document so kin-openapi can load it, see normalizeOpenAPI31Value for the schemas
+// root is set for the spec itself, the other documents are the ones it references
+func normalizeOpenAPI31(content json.RawMessage, root bool, config *parseConfig) (json.RawMessage, error) {
+	var document interface{}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI 3.1 document: %w", err)
+	}
+
+	if object, ok := document.(map[string]interface{}); ok && root {
+		// webhooks are requests the API sends, there is nothing for the model to call
+		if webhooks, ok := object["webhooks"].(map[string]interface{}); ok {
+			for _, name := range sortedKeys(webhooks) {
+				config.warn("/webhooks/"+escapePointer(name), "webhooks are not exposed as tools")
+			}
+		}
+		delete(object, "webhooks")
+		delete(object, "jsonSchemaDialect")
+
+		// 3.1 specs may only have webhooks or components, 3.0 always needs paths
+		if _, ok := object["paths"]; !ok {
+			object["paths"] = map[string]interface{}{}
+		}
+		inlinePathItems(object)
+	}
+
+	normalizeOpenAPI31Value(document)
+
+	normalized, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert OpenAPI 3.1 document: %w", err)
+	}
+	return normalized, nil
+}
+
+// Replaces the references to components.pathItems, which 3.0 doesn't have, with the path items themselves
+func inlinePathItems(document map[string]interface{}) {
+	components, _ := document["components"].(map[string]interface{})
+	if components == nil {
+		return
+	}
+	pathItems, _ := components["pathItems"].(map[string]interface{})
+	delete(components, "pathItems")
+	if pathItems == nil {
+		return
+	}
+
+	paths, _ := document["paths"].(map[string]interface{})
+	for path, item := range paths {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ref, _ := object["$ref"].(string)
+		name, ok := strings.CutPrefix(ref, "#/components/pathItems/")
+		if !ok {
+			continue
+		}
+		target, ok := pathItems[unescapePointer(name)].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		inlined := make(map[string]interface{}, len(target))
+		for key, value := range target {
+			inlined[key] = value
+		}
+		// a summary or description next to the $ref overrides the one of the path item
+		for key, value := range object {
+			if key != "$ref" {
+				inlined[key] = value
+			}
+		}
+		paths[path] = inlined
+	}
+}
+
+// Rewrites the schemas in a value into the 3.0 shape where the meaning survives:
+//   - type arrays with "null" become nullable, a lone "null" type becomes const null
+//   - numeric exclusiveMinimum and exclusiveMaximum become minimum and maximum with the exclusive flag
+//   - arrays without items get an empty items schema, 3.1 arrays may only have prefixItems
+//
+// Values that are data rather than schemas, like examples and defaults, are left alone
+func normalizeOpenAPI31Value(value interface{}) {
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			normalizeOpenAPI31Value(item)
+		}
+	case map[string]interface{}:
+		normalizeOpenAPI31Schema(value)
+		for key, child := range value {
+			switch key {
+			case "example", "examples", "default", "enum", "const":
+				continue
+			}
+			if strings.HasPrefix(key, "x-") {
+				continue
+			}
+			if named, ok := child.(map[string]interface{}); ok && namedMaps[key] {
+				// the keys are names, a property may well be called default
+				for _, entry := range named {
+					normalizeOpenAPI31Value(entry)
+				}
+				continue
+			}
+			normalizeOpenAPI31Value(child)
+		}
+	}
+}
+
+// namedMaps are the keys whose value maps names chosen by the spec author to schemas or objects holding them,
+// e.g. properties or responses, where default is a response rather than a default value
+var namedMaps = map[string]bool{
+	"properties":        true,
+	"patternProperties": true,
+	"dependentSchemas":  true,
+	"$defs":             true,
+	"definitions":       true,
+	"schemas":           true,
+	"parameters":        true,
+	"requestBodies":     true,
+	"responses":         true,
+	"headers":           true,
+	"content":           true,
+	"encoding":          true,
+	"callbacks":         true,
+	"paths":             true,
+}
+
+func normalizeOpenAPI31Schema(schema map[string]interface{}) {
+	if types, ok := schemaTypes(schema["type"]); ok {
+		var kept []interface{}
+		nullable := false
+		for _, t := range types {
+			if t == "null" {
+				nullable = true
+				continue
+			}
+			kept = append(kept, t)
+		}
+
+		switch {
+		case nullable && len(kept) == 0:
+			delete(schema, "type")
+			schema["const"] = nil
+		case nullable:
+			schema["type"] = kept
+			schema["nullable"] = true
+		}
+
+		if containsValue(kept, "array") {
+			if _, ok := schema["items"]; !ok {
+				schema["items"] = map[string]interface{}{}
+			}
+		}
+	}
+
+	for _, bound := range []struct{ exclusive, inclusive string }{
+		{"exclusiveMinimum", "minimum"},
+		{"exclusiveMaximum", "maximum"},
+	} {
+		limit, ok := schema[bound.exclusive].(float64)
+		if !ok {
+			continue
+		}
+		inclusive, hasInclusive := schema[bound.inclusive].(float64)
+		tighter := bound.exclusive == "exclusiveMinimum" && inclusive > limit || bound.exclusive == "exclusiveMaximum" && inclusive < limit
+		if hasInclusive && tighter {
+			// the inclusive bound already rules out the exclusive one
+			delete(schema, bound.exclusive)
+			continue
+		}
+		schema[bound.inclusive] = limit
+		schema[bound.exclusive] = true
+	}
+}
+
+// Reads the type of a schema, which 3.1 allows to be a list
+// A type that isn't a string or a list of strings means the object isn't a schema, e.g. a property named type
+func schemaTypes(value interface{}) ([]interface{}, bool) {
+	switch value := value.(type) {
+	case string:
+		return []interface{}{value}, true
+	case []interface{}:
+		for _, t := range value {
+			if _, ok := t.(string); !ok {
+				return nil, false
+			}
+		}
+		return value, true
+	}
+	return nil, false
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func unescapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
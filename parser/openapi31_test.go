@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSpecRouter_OpenAPI31(t *testing.T) {
+	var diagnostics Diagnostics
+	tools, err := ParseSpecRouter(filepath.Join("testdata", "petstore-3.1.yaml"), WithDiagnostics(&diagnostics))
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	assert.Equal(t, "createPet", tools[0].Name)
+	assert.Equal(t, "https://pets.example.com/pets", tools[0].Operation.URL)
+	assert.Contains(t, diagnostics, Diagnostic{Severity: SeverityWarning, Pointer: "/webhooks/newPet", Message: "webhooks are not exposed as tools"})
+
+	body := tools[0].InputSchema.Properties["body"].(map[string]interface{})
+	properties := body["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "examples": []interface{}{"Rex", "Tom"}}, properties["name"])
+	assert.Equal(t, map[string]interface{}{"const": "dog"}, properties["kind"])
+	assert.Equal(t, map[string]interface{}{"type": []string{"string", "null"}}, properties["nickname"])
+	assert.Equal(t, map[string]interface{}{"type": "integer", "exclusiveMinimum": float64(0)}, properties["age"])
+	assert.Equal(t, map[string]interface{}{"const": nil}, properties["parent"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "number"},
+		map[string]interface{}{"type": "number"},
+	}, properties["location"].(map[string]interface{})["prefixItems"])
+}
+
+func TestNormalizeOpenAPI31Value(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":             []interface{}{"integer", "null"},
+		"minimum":          float64(5),
+		"exclusiveMinimum": float64(1),
+		"exclusiveMaximum": float64(10),
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{"type": "string"},
+		},
+		"default":  map[string]interface{}{"type": []interface{}{"null"}},
+		"x-custom": map[string]interface{}{"exclusiveMaximum": float64(3)},
+	}
+
+	normalizeOpenAPI31Value(schema)
+
+	assert.Equal(t, map[string]interface{}{
+		"type":             []interface{}{"integer"},
+		"nullable":         true,
+		"minimum":          float64(5),
+		"maximum":          float64(10),
+		"exclusiveMaximum": true,
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{"type": "string"},
+		},
+		"default":  map[string]interface{}{"type": []interface{}{"null"}},
+		"x-custom": map[string]interface{}{"exclusiveMaximum": float64(3)},
+	}, schema)
+}
+
+func TestNormalizeOpenAPI31Value_PropertiesNamedLikeKeywords(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"default": map[string]interface{}{"type": []interface{}{"string", "null"}},
+			"enum":    map[string]interface{}{"type": []interface{}{"null"}},
+		},
+		"$defs": map[string]interface{}{
+			"const": map[string]interface{}{"type": []interface{}{"integer", "null"}},
+		},
+		"patternProperties": map[string]interface{}{
+			"^examples": map[string]interface{}{"type": []interface{}{"boolean", "null"}},
+		},
+	}
+	responses := map[string]interface{}{
+		"responses": map[string]interface{}{
+			"default": map[string]interface{}{
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{"type": []interface{}{"string", "null"}},
+					},
+				},
+			},
+		},
+	}
+
+	normalizeOpenAPI31Value(schema)
+	normalizeOpenAPI31Value(responses)
+
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": []interface{}{"string"}, "nullable": true}, properties["default"])
+	assert.Equal(t, map[string]interface{}{"const": nil}, properties["enum"])
+	assert.Equal(t, map[string]interface{}{"type": []interface{}{"integer"}, "nullable": true}, schema["$defs"].(map[string]interface{})["const"])
+	assert.Equal(t, map[string]interface{}{"type": []interface{}{"boolean"}, "nullable": true}, schema["patternProperties"].(map[string]interface{})["^examples"])
+
+	content := responses["responses"].(map[string]interface{})["default"].(map[string]interface{})["content"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": []interface{}{"string"}, "nullable": true}, content["application/json"].(map[string]interface{})["schema"])
+}
@@ -0,0 +1,343 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/evisdrenova/axon-server/jsonschema"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertOpenAPIToMCPTools_PathParameters(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "servers": [{"url": "https://pets.example.com"}],
+  "paths": {
+    "/pets/{id}": {
+      "parameters": [
+        {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}},
+        {"name": "verbose", "in": "query", "schema": {"type": "boolean"}}
+      ],
+      "get": {
+        "operationId": "getPet",
+        "parameters": [{"name": "verbose", "in": "query", "description": "Include the owner", "schema": {"type": "boolean"}}],
+        "responses": {"200": {"description": "ok"}}
+      },
+      "delete": {"operationId": "deletePet", "responses": {"204": {"description": "deleted"}}},
+      "options": {"operationId": "petOptions", "responses": {"204": {"description": "allowed methods"}}},
+      "trace": {"operationId": "tracePet", "responses": {"200": {"description": "echo"}}}
+    }
+  }
+}`))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(loader.Context))
+
+	tools, err := ConvertOpenAPIToMCPTools(doc)
+	require.NoError(t, err)
+	require.Len(t, tools, 4)
+
+	get, deletePet := tools[0], tools[1]
+	assert.Equal(t, "getPet", get.Name)
+	assert.Equal(t, []Parameter{
+		{Name: "id", In: ParameterInPath, Style: StyleSimple, Required: true},
+		{Name: "verbose", In: ParameterInQuery, Style: StyleForm, Explode: true},
+	}, get.Operation.Parameters)
+	assert.Equal(t, map[string]interface{}{"type": "boolean", "description": "Include the owner"}, get.InputSchema.Properties["verbose"])
+
+	assert.Equal(t, "deletePet", deletePet.Name)
+	assert.Contains(t, deletePet.InputSchema.Properties, "id")
+	assert.Equal(t, []string{"id"}, deletePet.InputSchema.Required)
+
+	assert.Equal(t, "OPTIONS", tools[2].Operation.Method)
+	assert.Equal(t, "TRACE", tools[3].Operation.Method)
+}
+
+func TestConvertOpenAPIToMCPTools_RequestBodies(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {"content": {
+          "application/xml": {"schema": {"type": "object"}},
+          "application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}
+        }},
+        "responses": {"200": {"description": "ok"}}
+      }
+    },
+    "/pets/{id}/photos": {
+      "post": {
+        "operationId": "uploadPhotos",
+        "requestBody": {"required": true, "content": {
+          "multipart/form-data": {"schema": {"type": "object", "properties": {
+            "caption": {"type": "string"},
+            "photo": {"type": "string", "format": "binary", "description": "The photo"},
+            "extras": {"type": "array", "items": {"type": "string", "format": "binary"}}
+          }}}
+        }},
+        "responses": {"200": {"description": "ok"}}
+      }
+    },
+    "/pets/{id}/notes": {
+      "put": {
+        "operationId": "putNotes",
+        "requestBody": {"content": {"text/plain": {"schema": {"type": "string"}}}},
+        "responses": {"200": {"description": "ok"}}
+      }
+    },
+    "/pets/{id}/avatar": {
+      "put": {
+        "operationId": "putAvatar",
+        "requestBody": {"content": {"image/*": {"schema": {"type": "string", "format": "binary"}}}},
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`))
+	require.NoError(t, err)
+
+	tools, err := ConvertOpenAPIToMCPTools(doc)
+	require.NoError(t, err)
+	byName := make(map[string]Tool)
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+
+	create := byName["createPet"]
+	assert.Equal(t, &RequestBody{MediaType: "application/json", Encoding: BodyEncodingJSON}, create.Operation.Body)
+	assert.Equal(t, "Request body, sent as application/json", create.InputSchema.Properties["body"].(map[string]interface{})["description"])
+
+	upload := byName["uploadPhotos"]
+	assert.Equal(t, &RequestBody{MediaType: "multipart/form-data", Encoding: BodyEncodingMultipart, FileFields: []string{"extras", "photo"}}, upload.Operation.Body)
+	assert.Contains(t, upload.InputSchema.Required, "body")
+	properties := upload.InputSchema.Properties["body"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string"}, properties["caption"])
+	assert.Equal(t, "The photo", properties["photo"].(map[string]interface{})["description"])
+	assert.Equal(t, fileSchema(), properties["extras"].(map[string]interface{})["items"])
+
+	notes := byName["putNotes"]
+	assert.Equal(t, BodyEncodingText, notes.Operation.Body.Encoding)
+	assert.Equal(t, map[string]interface{}{
+		"type":             "string",
+		"contentMediaType": "text/plain",
+		"description":      "Request body, sent as text/plain",
+	}, notes.InputSchema.Properties["body"])
+
+	avatar := byName["putAvatar"]
+	assert.Equal(t, &RequestBody{MediaType: "image/*", Encoding: BodyEncodingBinary}, avatar.Operation.Body)
+}
+
+func TestConvertOpenAPIToMCPTools_ReadOnlyRequired(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  },
+  "components": {"schemas": {"Pet": {
+    "type": "object",
+    "required": ["id", "name", "password"],
+    "properties": {
+      "id": {"type": "integer", "readOnly": true},
+      "name": {"type": "string"},
+      "password": {"type": "string", "writeOnly": true}
+    }
+  }}}
+}`))
+	require.NoError(t, err)
+
+	tools, err := ConvertOpenAPIToMCPTools(doc)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	// the server assigns the id, a request can't be required to send it
+	body := tools[0].InputSchema.Properties["body"].(map[string]interface{})
+	assert.Equal(t, []string{"name", "password"}, body["required"])
+	assert.Empty(t, jsonschema.Validate(body, map[string]interface{}{"name": "Rex", "password": "secret"}))
+}
+
+func TestConvertOpenAPIToMCPTools_Responses(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "paths": {
+    "/pets/{id}": {
+      "get": {
+        "operationId": "getPet",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {
+          "200": {"description": "The pet", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+          "4XX": {"description": "Client error", "content": {"text/plain": {"schema": {"type": "string"}}}},
+          "default": {"description": "Something went wrong"}
+        }
+      }
+    }
+  },
+  "components": {"schemas": {"Pet": {
+    "type": "object",
+    "required": ["name", "password"],
+    "properties": {
+      "name": {"type": "string"},
+      "password": {"type": "string", "writeOnly": true},
+      "parent": {"$ref": "#/components/schemas/Pet"}
+    }
+  }}}
+}`))
+	require.NoError(t, err)
+
+	tools, err := ConvertOpenAPIToMCPTools(doc)
+	require.NoError(t, err)
+	operation := tools[0].Operation
+
+	ok, found := operation.ResponseFor(200)
+	require.True(t, found)
+	assert.Equal(t, "The pet", ok.Description)
+	assert.Equal(t, "#/$defs/Pet", ok.Schema["$ref"])
+	pet := ok.Schema["$defs"].(map[string]interface{})["Pet"].(map[string]interface{})
+	assert.Equal(t, "#/$defs/Pet", pet["properties"].(map[string]interface{})["parent"].(map[string]interface{})["$ref"])
+	// the password is only ever sent, a response without it is what the spec describes
+	assert.Equal(t, []string{"name"}, pet["required"])
+	assert.Empty(t, jsonschema.Validate(ok.Schema, map[string]interface{}{"name": "Rex"}))
+
+	clientError, _ := operation.ResponseFor(404)
+	assert.Equal(t, Response{Description: "Client error"}, clientError)
+
+	fallback, _ := operation.ResponseFor(503)
+	assert.Equal(t, Response{Description: "Something went wrong"}, fallback)
+}
+
+func TestConvertOpenAPIToMCPTools_Confirm(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "paths": {
+    "/pets": {
+      "get": {"operationId": "listPets", "responses": {"200": {"description": "ok"}}},
+      "post": {"operationId": "searchPets", "x-axon-confirm": false, "responses": {"200": {"description": "ok"}}},
+      "put": {"operationId": "replacePets", "responses": {"200": {"description": "ok"}}}
+    },
+    "/pets/export": {
+      "get": {"operationId": "exportPets", "x-axon-confirm": true, "responses": {"200": {"description": "ok"}}}
+    }
+  }
+}`))
+	require.NoError(t, err)
+
+	tools, err := ConvertOpenAPIToMCPTools(doc)
+	require.NoError(t, err)
+
+	safe := make(map[string]bool)
+	for _, tool := range tools {
+		safe[tool.Name] = tool.Operation.Safe()
+	}
+	assert.Equal(t, map[string]bool{"listPets": true, "searchPets": true, "replacePets": false, "exportPets": false}, safe)
+}
+
+func TestConvertOpenAPIToMCPTools_Extensions(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "paths": {
+    "/v1/pets": {
+      "get": {
+        "operationId": "ListPetsV1Endpoint",
+        "summary": "Lists pets",
+        "x-mcp-name": "list_pets",
+        "x-mcp-description": "Lists the pets in the store, newest first",
+        "x-mcp-examples": [{"species": "dog"}],
+        "parameters": [
+          {"name": "species", "in": "query", "schema": {"type": "string"}, "x-mcp-examples": ["dog", "cat"], "x-mcp-description": "Species to list"},
+          {"name": "api-version", "in": "header", "required": true, "schema": {"type": "string"}, "x-mcp-default": "2024-01-01"},
+          {"name": "debug", "in": "query", "schema": {"type": "boolean"}, "x-mcp-hidden": true},
+          {"name": "tenant", "in": "query", "required": true, "schema": {"type": "string"}, "x-mcp-hidden": true}
+        ],
+        "responses": {"200": {"description": "ok"}}
+      },
+      "delete": {"operationId": "purgePets", "x-mcp-hidden": true, "responses": {"204": {"description": "purged"}}}
+    }
+  }
+}`))
+	require.NoError(t, err)
+
+	var diagnostics Diagnostics
+	tools, err := ConvertOpenAPIToMCPTools(doc, WithDiagnostics(&diagnostics))
+	require.NoError(t, err)
+	require.Len(t, tools, 1, "hidden operations are left out")
+
+	tool := tools[0]
+	assert.Equal(t, "list_pets", tool.Name)
+	assert.Equal(t, "ListPetsV1Endpoint", tool.Operation.ID)
+	assert.Equal(t, "Lists the pets in the store, newest first\n\nExample arguments:\n- {\"species\":\"dog\"}", tool.Description)
+
+	assert.Equal(t, map[string]interface{}{
+		"type":        "string",
+		"description": "Species to list",
+		"examples":    []interface{}{"dog", "cat"},
+	}, tool.InputSchema.Properties["species"])
+	assert.NotContains(t, tool.InputSchema.Properties, "api-version")
+	assert.NotContains(t, tool.InputSchema.Properties, "debug")
+	assert.Contains(t, tool.InputSchema.Properties, "tenant", "a required parameter without a default can't be hidden")
+	assert.Equal(t, []string{"tenant"}, tool.InputSchema.Required)
+
+	assert.Equal(t, "2024-01-01", tool.Operation.Parameters[1].Fixed)
+	require.Len(t, diagnostics, 2)
+	assert.Equal(t, "/paths/~1v1~1pets/delete", diagnostics[0].Pointer)
+	assert.Equal(t, "left out, the operation is marked x-mcp-hidden", diagnostics[0].Message)
+	assert.Contains(t, diagnostics[1].Message, "parameter tenant is required")
+}
+
+func TestConvertOpenAPIToMCPTools_SharedParameterName(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "servers": [{"url": "https://pets.example.com"}],
+  "paths": {
+    "/pets/{id}": {
+      "get": {
+        "operationId": "getPet",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}},
+          {"name": "id", "in": "query", "schema": {"type": "string"}},
+          {"name": "verbose", "in": "query", "schema": {"type": "boolean"}}
+        ],
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`))
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(loader.Context))
+
+	tools, err := ConvertOpenAPIToMCPTools(doc)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	tool := tools[0]
+	assert.Equal(t, []Parameter{
+		{Name: "id", In: ParameterInPath, Style: StyleSimple, Required: true, Argument: "path.id"},
+		{Name: "id", In: ParameterInQuery, Style: StyleForm, Explode: true, Argument: "query.id"},
+		{Name: "verbose", In: ParameterInQuery, Style: StyleForm, Explode: true},
+	}, tool.Operation.Parameters)
+	assert.Equal(t, map[string]interface{}{"type": "integer"}, tool.InputSchema.Properties["path.id"])
+	assert.Equal(t, map[string]interface{}{"type": "string"}, tool.InputSchema.Properties["query.id"])
+	assert.NotContains(t, tool.InputSchema.Properties, "id")
+	assert.Contains(t, tool.InputSchema.Properties, "verbose")
+	assert.Equal(t, []string{"path.id"}, tool.InputSchema.Required)
+}
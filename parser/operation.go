@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"net/http"
+
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// HTTP methods in the order their operations are turned into tools
+var httpMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS", "TRACE", "CONNECT"}
+
+// Parameter locations as defined by the OpenAPI spec
+const (
+	ParameterInPath   = "path"
+	ParameterInQuery  = "query"
+	ParameterInHeader = "header"
+	ParameterInCookie = "cookie"
+)
+
+// Serialization styles as defined by the OpenAPI spec
+// tabDelimited isn't part of OpenAPI 3 but is needed for Swagger's tsv collection format
+const (
+	StyleSimple         = "simple"
+	StyleLabel          = "label"
+	StyleMatrix         = "matrix"
+	StyleForm           = "form"
+	StyleSpaceDelimited = "spaceDelimited"
+	StylePipeDelimited  = "pipeDelimited"
+	StyleTabDelimited   = "tabDelimited"
+	StyleDeepObject     = "deepObject"
+)
+
+// Tool pairs the MCP tool that is shown to the model with the HTTP operation that it calls
+type Tool struct {
+	mcp.Tool
+	Operation Operation
+}
+
+// Operation holds everything the handler needs to know to build the HTTP request for a tool
+type Operation struct {
+	// ID is the operationId, or the synthesized name of an operation without one, e.g. get_pets_by_petId
+	ID         string
+	Method     string
+	URL        string
+	Parameters []Parameter
+	Security   []SecurityRequirement
+	// Body is nil when the operation doesn't take a request body
+	Body *RequestBody
+	// Responses are keyed by status code, a range like 2XX, or default
+	Responses map[string]Response
+	// Confirm is set by the x-axon-confirm extension, nil leaves it to the method whether the operation is safe
+	Confirm *bool
+}
+
+// Safe reports whether the operation may run without the user confirming it first
+// Unless the spec or config says otherwise only GET, HEAD, OPTIONS and TRACE are safe
+func (o Operation) Safe() bool {
+	if o.Confirm != nil {
+		return !*o.Confirm
+	}
+	switch o.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// Parameter describes where an argument goes in the request and how it is serialized
+type Parameter struct {
+	Name     string
+	In       string
+	Style    string
+	Explode  bool
+	Required bool
+	// Fixed is sent whatever the arguments say, the parameter isn't shown to the model (x-mcp-default)
+	Fixed interface{}
+	// Argument is the tool argument holding the value when it isn't the name, see ArgumentName
+	Argument string
+}
+
+// ArgumentName is the tool argument that holds the value of the parameter
+// Parameters that share their name with one in another location are told apart by it, e.g. query.id and path.id
+func (p Parameter) ArgumentName() string {
+	if p.Argument != "" {
+		return p.Argument
+	}
+	return p.Name
+}
+
+// Finds the names used by parameters in more than one location, keyed by name
+type parameterLocations map[string]map[string]bool
+
+func (l parameterLocations) add(name string, in string) {
+	if l[name] == nil {
+		l[name] = make(map[string]bool)
+	}
+	l[name][in] = true
+}
+
+// The tool argument of a parameter, its name unless another location uses the same name
+func (l parameterLocations) argument(name string, in string) string {
+	if len(l[name]) > 1 {
+		return in + "." + name
+	}
+	return name
+}
+
+// Builds the parameter metadata from an OpenAPI parameter, applying the default style and explode values for its location
+func newOpenAPIParameter(param *openapi3.Parameter) (Parameter, error) {
+	method, err := param.SerializationMethod()
+	if err != nil {
+		return Parameter{}, err
+	}
+
+	return Parameter{
+		Name:     param.Name,
+		In:       param.In,
+		Style:    method.Style,
+		Explode:  method.Explode,
+		Required: param.Required,
+	}, nil
+}
+
+// Builds the parameter metadata from a Swagger parameter by mapping its collectionFormat onto the equivalent OpenAPI 3 style
+func newSwaggerParameter(name, in, collectionFormat string, required bool) Parameter {
+	param := Parameter{
+		Name:     name,
+		In:       in,
+		Required: required,
+	}
+
+	if in == ParameterInPath || in == ParameterInHeader {
+		param.Style = StyleSimple
+		return param
+	}
+
+	switch collectionFormat {
+	case "multi":
+		param.Style = StyleForm
+		param.Explode = true
+	case "ssv":
+		param.Style = StyleSpaceDelimited
+	case "tsv":
+		param.Style = StyleTabDelimited
+	case "pipes":
+		param.Style = StylePipeDelimited
+	default:
+		param.Style = StyleForm
+	}
+
+	return param
+}
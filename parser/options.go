@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ParseOption is a function that configures how a spec is converted to tools.
+type ParseOption func(*parseConfig)
+
+// parseConfig holds the settings used while converting a spec
+type parseConfig struct {
+	serverIndex       int
+	serverDescription string
+	serverVariables   map[string]string
+	specLocation      string
+	baseURL           string
+	filter            Filter
+	toolPrefix        string
+	diagnostics       *Diagnostics
+	lenient           bool
+	overlay           *Overlay
+	specHeaders       map[string]string
+	specCacheDir      string
+	specTransport     http.RoundTripper
+	fetcher           *specFetcher
+	sources           *[]string
+}
+
+func newParseConfig(opts []ParseOption) *parseConfig {
+	config := &parseConfig{
+		serverIndex:     -1,
+		serverVariables: make(map[string]string),
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+// WithServer selects the server requests are sent to when the spec lists more than one
+// The selector is either the index of the server or a case insensitive part of its description, e.g. "staging"
+func WithServer(selector string) ParseOption {
+	return func(c *parseConfig) {
+		if index, err := strconv.Atoi(selector); err == nil {
+			c.serverIndex = index
+			c.serverDescription = ""
+			return
+		}
+		c.serverIndex = -1
+		c.serverDescription = selector
+	}
+}
+
+// WithServerVariables overrides the default values of server URL variables
+func WithServerVariables(variables map[string]string) ParseOption {
+	return func(c *parseConfig) {
+		for name, value := range variables {
+			c.serverVariables[name] = value
+		}
+	}
+}
+
+// WithSpecLocation sets where the spec was loaded from so relative server URLs can be resolved against it
+func WithSpecLocation(location string) ParseOption {
+	return func(c *parseConfig) {
+		c.specLocation = location
+	}
+}
+
+// WithBaseURL sends every request to the given base URL instead of the servers declared in the spec
+func WithBaseURL(baseURL string) ParseOption {
+	return func(c *parseConfig) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithFilter only converts the operations the filter allows
+func WithFilter(filter Filter) ParseOption {
+	return func(c *parseConfig) {
+		c.filter = filter
+	}
+}
+
+// WithToolPrefix namespaces the tools of a spec, e.g. the prefix petstore turns getPetById into petstore_getPetById
+func WithToolPrefix(prefix string) ParseOption {
+	return func(c *parseConfig) {
+		c.toolPrefix = prefix
+	}
+}
+
+// Prepends the configured prefix to a tool name
+func (c *parseConfig) toolName(name string) string {
+	if c.toolPrefix == "" {
+		return name
+	}
+	return c.toolPrefix + "_" + name
+}
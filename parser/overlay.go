@@ -0,0 +1,269 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+	"gopkg.in/yaml.v3"
+)
+
+// Overlay changes how the operations of a spec are turned into tools without editing the spec
+// It is applied right after the spec is loaded by setting the x-mcp-* extensions of the operations it targets
+type Overlay struct {
+	// Operations are keyed by operationId or by method and path, e.g. "GET /pets/{petId}"
+	Operations map[string]OperationOverlay `yaml:"operations"`
+}
+
+// OperationOverlay patches a single operation
+type OperationOverlay struct {
+	// Name renames the tool, like x-mcp-name
+	Name        string        `yaml:"name"`
+	Description string        `yaml:"description"`
+	Hidden      *bool         `yaml:"hidden"`
+	Examples    []interface{} `yaml:"examples"`
+	// Parameters are keyed by parameter name
+	Parameters map[string]ParameterOverlay `yaml:"parameters"`
+}
+
+// ParameterOverlay patches a parameter of an operation
+type ParameterOverlay struct {
+	Description string `yaml:"description"`
+	Hidden      *bool  `yaml:"hidden"`
+	// Default pins the value of the parameter, like x-mcp-default
+	Default  interface{}   `yaml:"default"`
+	Examples []interface{} `yaml:"examples"`
+}
+
+// LoadOverlay reads a YAML or JSON overlay file
+func LoadOverlay(path string) (*Overlay, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlay: %w", err)
+	}
+
+	var overlay Overlay
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&overlay); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid overlay %s: %w", path, err)
+	}
+	return &overlay, nil
+}
+
+// WithOverlay applies an overlay to the spec when it is loaded, an overlay target that matches nothing is an error
+func WithOverlay(overlay *Overlay) ParseOption {
+	return func(c *parseConfig) {
+		c.overlay = overlay
+	}
+}
+
+// Turns the operation patch into the extensions it stands for
+func (o OperationOverlay) extensions() map[string]interface{} {
+	extensions := make(map[string]interface{})
+	if o.Name != "" {
+		extensions[ExtensionName] = o.Name
+	}
+	if o.Description != "" {
+		extensions[ExtensionDescription] = o.Description
+	}
+	if o.Hidden != nil {
+		extensions[ExtensionHidden] = *o.Hidden
+	}
+	if len(o.Examples) > 0 {
+		extensions[ExtensionExamples] = o.Examples
+	}
+	return extensions
+}
+
+// Turns the parameter patch into the extensions it stands for
+func (p ParameterOverlay) extensions() map[string]interface{} {
+	extensions := make(map[string]interface{})
+	if p.Description != "" {
+		extensions[ExtensionDescription] = p.Description
+	}
+	if p.Hidden != nil {
+		extensions[ExtensionHidden] = *p.Hidden
+	}
+	if p.Default != nil {
+		extensions[ExtensionDefault] = p.Default
+	}
+	if len(p.Examples) > 0 {
+		extensions[ExtensionExamples] = p.Examples
+	}
+	return extensions
+}
+
+// Returns a copy of the extensions with the patch applied, extensions may be shared with other parts of the spec
+func patchExtensions(extensions map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	patched := make(map[string]interface{}, len(extensions)+len(patch))
+	for name, value := range extensions {
+		patched[name] = value
+	}
+	for name, value := range patch {
+		patched[name] = value
+	}
+	return patched
+}
+
+// overlayTarget is an operation an overlay can point at
+// apply patches the operation and returns the names of the parameters it couldn't find
+type overlayTarget struct {
+	id     string
+	method string
+	path   string
+	apply  func(OperationOverlay) []string
+}
+
+// Matches the targets of an overlay against the operations of a spec and applies it
+// Every target of the overlay and every parameter it names has to match
+func applyOverlay(overlay *Overlay, operations []*overlayTarget) error {
+	var errs []error
+	for _, key := range sortedKeys(overlay.Operations) {
+		patch := overlay.Operations[key]
+
+		var matches []*overlayTarget
+		for _, op := range operations {
+			if op.id == key || strings.EqualFold(op.method+" "+op.path, key) {
+				matches = append(matches, op)
+			}
+		}
+		if len(matches) == 0 {
+			errs = append(errs, fmt.Errorf("overlay: operations.%s matches no operation", key))
+			continue
+		}
+
+		for _, op := range matches {
+			for _, missing := range op.apply(patch) {
+				errs = append(errs, fmt.Errorf("overlay: operations.%s.parameters.%s matches no parameter of %s %s", key, missing, op.method, op.path))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Applies an overlay to an OpenAPI document
+func applyOpenAPIOverlay(doc *openapi3.T, overlay *Overlay) error {
+	var targets []*overlayTarget
+	paths := doc.Paths.Map()
+	for _, path := range sortedKeys(paths) {
+		pathItem := paths[path]
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range httpMethods {
+			operation := pathItem.GetOperation(method)
+			if operation == nil {
+				continue
+			}
+			targets = append(targets, &overlayTarget{
+				id:     operation.OperationID,
+				method: method,
+				path:   path,
+				apply: func(patch OperationOverlay) []string {
+					operation.Extensions = patchExtensions(operation.Extensions, patch.extensions())
+
+					var missing []string
+					for _, name := range sortedKeys(patch.Parameters) {
+						extensions := patch.Parameters[name].extensions()
+						found := false
+						for i, ref := range operation.Parameters {
+							if ref == nil || ref.Value == nil || ref.Value.Name != name {
+								continue
+							}
+							// the parameter may be a shared component, so it is copied before it is changed
+							param := *ref.Value
+							param.Extensions = patchExtensions(param.Extensions, extensions)
+							operation.Parameters[i] = &openapi3.ParameterRef{Value: &param}
+							found = true
+						}
+						// path level parameters are overridden by an operation level copy
+						for _, ref := range pathItem.Parameters {
+							if ref == nil || ref.Value == nil || ref.Value.Name != name || operation.Parameters.GetByInAndName(ref.Value.In, name) != nil {
+								continue
+							}
+							param := *ref.Value
+							param.Extensions = patchExtensions(param.Extensions, extensions)
+							operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{Value: &param})
+							found = true
+						}
+						if !found {
+							missing = append(missing, name)
+						}
+					}
+					return missing
+				},
+			})
+		}
+	}
+	return applyOverlay(overlay, targets)
+}
+
+// Applies an overlay to a Swagger document
+func applySwaggerOverlay(doc *spec.Swagger, overlay *Overlay) error {
+	var targets []*overlayTarget
+	if doc.Paths != nil {
+		for _, path := range sortedKeys(doc.Paths.Paths) {
+			pathItem := doc.Paths.Paths[path]
+			for _, op := range []struct {
+				method    string
+				operation *spec.Operation
+			}{
+				{"GET", pathItem.Get},
+				{"POST", pathItem.Post},
+				{"PUT", pathItem.Put},
+				{"DELETE", pathItem.Delete},
+				{"PATCH", pathItem.Patch},
+				{"HEAD", pathItem.Head},
+				{"OPTIONS", pathItem.Options},
+			} {
+				operation := op.operation
+				if operation == nil {
+					continue
+				}
+				targets = append(targets, &overlayTarget{
+					id:     operation.ID,
+					method: op.method,
+					path:   path,
+					apply: func(patch OperationOverlay) []string {
+						operation.Extensions = patchExtensions(operation.Extensions, patch.extensions())
+
+						var missing []string
+						for _, name := range sortedKeys(patch.Parameters) {
+							extensions := patch.Parameters[name].extensions()
+							found := false
+							overridden := make(map[string]bool)
+							for i, param := range operation.Parameters {
+								if param.Name != name {
+									continue
+								}
+								operation.Parameters[i].Extensions = patchExtensions(param.Extensions, extensions)
+								overridden[param.In] = true
+								found = true
+							}
+							// path level parameters are overridden by an operation level copy
+							for _, param := range pathItem.Parameters {
+								if param.Name != name || overridden[param.In] {
+									continue
+								}
+								param.Extensions = patchExtensions(param.Extensions, extensions)
+								operation.Parameters = append(operation.Parameters, param)
+								found = true
+							}
+							if !found {
+								missing = append(missing, name)
+							}
+						}
+						return missing
+					},
+				})
+			}
+		}
+	}
+	return applyOverlay(overlay, targets)
+}
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const overlayOpenAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "servers": [{"url": "https://pets.example.com"}],
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "description": "Returns all pets from the system that the user has access to",
+        "parameters": [{"$ref": "#/components/parameters/limit"}],
+        "responses": {"200": {"description": "ok"}}
+      },
+      "delete": {"operationId": "purgePets", "responses": {"204": {"description": "purged"}}}
+    },
+    "/owners/{ownerId}/pets": {
+      "parameters": [{"name": "ownerId", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "get": {
+        "operationId": "listOwnerPets",
+        "parameters": [{"$ref": "#/components/parameters/limit"}],
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "limit": {"name": "limit", "in": "query", "schema": {"type": "integer"}}
+    }
+  }
+}`
+
+func TestLoadOpenApiSpec_Overlay(t *testing.T) {
+	specPath := writeSpec(t, t.TempDir(), "openapi.json", overlayOpenAPISpec)
+	overlay, err := LoadOverlay(writeSpec(t, t.TempDir(), "overlay.yaml", `
+operations:
+  listPets:
+    name: list_pets
+    description: Lists the pets
+    examples:
+      - limit: 5
+    parameters:
+      limit:
+        description: How many pets to return
+  GET /owners/{ownerId}/pets:
+    parameters:
+      ownerId:
+        default: me
+  purgePets:
+    hidden: true
+`))
+	require.NoError(t, err)
+
+	doc, err := LoadOpenApiSpec(specPath, WithOverlay(overlay))
+	require.NoError(t, err)
+
+	tools, err := ConvertOpenAPIToMCPTools(doc)
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+
+	assert.Equal(t, "listOwnerPets", tools[0].Name)
+	assert.NotContains(t, tools[0].InputSchema.Properties, "ownerId")
+	// the shared limit parameter is only patched for listPets
+	assert.NotContains(t, tools[0].InputSchema.Properties["limit"], "description")
+
+	assert.Equal(t, "list_pets", tools[1].Name)
+	assert.Equal(t, "Lists the pets\n\nExample arguments:\n- {\"limit\":5}", tools[1].Description)
+	assert.Equal(t, "How many pets to return", tools[1].InputSchema.Properties["limit"].(map[string]interface{})["description"])
+
+	var fixed interface{}
+	for _, param := range tools[0].Operation.Parameters {
+		if param.Name == "ownerId" {
+			fixed = param.Fixed
+		}
+	}
+	assert.Equal(t, "me", fixed)
+}
+
+func TestLoadSwaggerSpec_Overlay(t *testing.T) {
+	specPath := writeSpec(t, t.TempDir(), "swagger.json", `{
+  "swagger": "2.0",
+  "info": {"title": "pets", "version": "1"},
+  "host": "pets.example.com",
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "getPets",
+        "parameters": [{"name": "format", "in": "query", "type": "string"}],
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`)
+	overlay, err := LoadOverlay(writeSpec(t, t.TempDir(), "overlay.json", `{
+  "operations": {
+    "getPets": {"name": "list_pets", "parameters": {"format": {"default": "json"}}}
+  }
+}`))
+	require.NoError(t, err)
+
+	doc, err := LoadSwaggerSpec(specPath, WithOverlay(overlay))
+	require.NoError(t, err)
+
+	tools, err := ConvertSwaggerToMCPTools(doc)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	assert.Equal(t, "list_pets", tools[0].Name)
+	assert.NotContains(t, tools[0].InputSchema.Properties, "format")
+	assert.Equal(t, "json", tools[0].Operation.Parameters[0].Fixed)
+}
+
+func TestLoadOpenApiSpec_OverlayUnmatched(t *testing.T) {
+	specPath := writeSpec(t, t.TempDir(), "openapi.json", overlayOpenAPISpec)
+	overlay, err := LoadOverlay(writeSpec(t, t.TempDir(), "overlay.yaml", `
+operations:
+  getPet:
+    name: get_pet
+  listPets:
+    parameters:
+      offset:
+        hidden: true
+`))
+	require.NoError(t, err)
+
+	_, err = LoadOpenApiSpec(specPath, WithOverlay(overlay))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "operations.getPet matches no operation")
+	assert.Contains(t, err.Error(), "operations.listPets.parameters.offset matches no parameter of GET /pets")
+}
+
+func TestLoadOverlay_UnknownField(t *testing.T) {
+	_, err := LoadOverlay(writeSpec(t, t.TempDir(), "overlay.yaml", `
+operations:
+  listPets:
+    title: Lists the pets
+`))
+	assert.Error(t, err)
+}
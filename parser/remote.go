@@ -0,0 +1,298 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultSpecFetchTimeout bounds how long fetching a remote spec, or a document it references, may take
+const DefaultSpecFetchTimeout = 30 * time.Second
+
+// WithSpecHeaders sends the headers when fetching a remote spec and the documents it references
+// e.g. an Authorization header for a private spec endpoint
+func WithSpecHeaders(headers map[string]string) ParseOption {
+	return func(c *parseConfig) {
+		c.specHeaders = headers
+	}
+}
+
+// WithSpecCache keeps a copy of every remote document in dir and revalidates it with its ETag
+// When the spec can't be fetched, e.g. while offline, the cached copy is used instead
+func WithSpecCache(dir string) ParseOption {
+	return func(c *parseConfig) {
+		c.specCacheDir = dir
+	}
+}
+
+// WithSpecTransport fetches remote specs with the transport, e.g. one that goes through a proxy
+func WithSpecTransport(transport http.RoundTripper) ParseOption {
+	return func(c *parseConfig) {
+		c.specTransport = transport
+	}
+}
+
+// WithSources appends the location of every document the spec is read from to sources: the spec itself and the
+// files or URLs its $refs point at, which is what has to be watched to notice the spec changed
+func WithSources(sources *[]string) ParseOption {
+	return func(c *parseConfig) {
+		c.sources = sources
+	}
+}
+
+func (c *parseConfig) recordSource(location *url.URL) {
+	if c.sources == nil {
+		return
+	}
+	if location.Scheme == "" {
+		*c.sources = append(*c.sources, filepath.FromSlash(location.Path))
+		return
+	}
+	*c.sources = append(*c.sources, location.String())
+}
+
+// Passes the fetcher on so the spec isn't fetched again by every step of loading it
+func withSpecFetcher(fetcher *specFetcher) ParseOption {
+	return func(c *parseConfig) {
+		c.fetcher = fetcher
+	}
+}
+
+// Returns the fetcher shared by the steps that read the spec, or a new one
+func (c *parseConfig) specFetcher(specPath string) (*specFetcher, error) {
+	if c.fetcher != nil {
+		return c.fetcher, nil
+	}
+	return newSpecFetcher(specPath, c)
+}
+
+// specFetcher reads a spec and the documents its $refs point at
+// $refs are only followed to where the spec itself lives, the directory of a local spec or the host of a remote one
+type specFetcher struct {
+	root      *url.URL
+	client    *http.Client
+	headers   map[string]string
+	cacheDir  string
+	config    *parseConfig
+	documents map[string]*specDocument
+}
+
+func newSpecFetcher(specPath string, config *parseConfig) (*specFetcher, error) {
+	var root *url.URL
+	if IsURL(specPath) {
+		parsed, err := url.Parse(specPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec URL: %w", err)
+		}
+		root = parsed
+	} else {
+		absolute, err := filepath.Abs(specPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve spec path: %w", err)
+		}
+		root = &url.URL{Path: filepath.ToSlash(absolute)}
+	}
+
+	return &specFetcher{
+		root:      root,
+		client:    &http.Client{Transport: config.specTransport, Timeout: DefaultSpecFetchTimeout},
+		headers:   config.specHeaders,
+		cacheDir:  config.specCacheDir,
+		config:    config,
+		documents: make(map[string]*specDocument),
+	}, nil
+}
+
+// remote reports whether the spec was loaded from a URL
+func (f *specFetcher) remote() bool {
+	return f.root.Scheme != ""
+}
+
+// Reads the spec itself
+func (f *specFetcher) readRoot() (*specDocument, error) {
+	return f.read(f.root)
+}
+
+// Reads a document, each document is only read and parsed once
+func (f *specFetcher) read(location *url.URL) (*specDocument, error) {
+	// go-openapi hands local documents over as file:// URLs
+	if location.Scheme == "file" {
+		location = &url.URL{Path: location.Path}
+	}
+	location = &url.URL{Scheme: location.Scheme, User: location.User, Host: location.Host, Path: location.Path, RawQuery: location.RawQuery}
+
+	key := location.String()
+	if document, ok := f.documents[key]; ok {
+		return document, nil
+	}
+	if err := f.allowed(location); err != nil {
+		return nil, err
+	}
+	f.config.recordSource(location)
+
+	var content []byte
+	var err error
+	if location.Scheme == "" {
+		content, err = os.ReadFile(filepath.FromSlash(location.Path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spec file: %w", err)
+		}
+	} else {
+		content, err = f.fetch(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	document, err := parseSpecDocument(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+
+	f.documents[key] = document
+	return document, nil
+}
+
+// Keeps $refs from reaching documents the spec has no business reading, like files elsewhere on disk
+func (f *specFetcher) allowed(location *url.URL) error {
+	if f.remote() {
+		if location.Scheme != f.root.Scheme || location.Host != f.root.Host {
+			return fmt.Errorf("$ref to %s is not allowed, a remote spec may only reference documents on %s://%s", location, f.root.Scheme, f.root.Host)
+		}
+		return nil
+	}
+
+	dir := path.Dir(f.root.Path)
+	if location.Scheme != "" || location.Host != "" || !strings.HasPrefix(path.Clean(location.Path), strings.TrimSuffix(dir, "/")+"/") {
+		return fmt.Errorf("$ref to %s is not allowed, a local spec may only reference files in %s", location, filepath.FromSlash(dir))
+	}
+	return nil
+}
+
+// cachedDocument is a remote document kept on disk
+type cachedDocument struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+// Fetches a remote document, revalidating the cached copy when there is one
+func (f *specFetcher) fetch(location string) ([]byte, error) {
+	cached := f.readCache(location)
+
+	req, err := http.NewRequest(http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", location, err)
+	}
+	req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.8")
+	for name, value := range f.headers {
+		req.Header.Set(name, value)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if cached != nil {
+			f.config.warn("", "failed to fetch %s, using the cached copy: %v", location, err)
+			return cached.Body, nil
+		}
+		return nil, fmt.Errorf("failed to fetch %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached.Body, nil
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", location, err)
+		}
+		f.writeCache(cachedDocument{
+			URL:          location,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Body:         body,
+		})
+		return body, nil
+	case resp.StatusCode >= 500 && cached != nil:
+		f.config.warn("", "failed to fetch %s, using the cached copy: %s", location, resp.Status)
+		return cached.Body, nil
+	default:
+		return nil, fmt.Errorf("failed to fetch %s: %s", location, resp.Status)
+	}
+}
+
+func (f *specFetcher) cachePath(location string) string {
+	sum := sha256.Sum256([]byte(location))
+	return filepath.Join(f.cacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Returns the cached copy of a document, nil when there is none
+func (f *specFetcher) readCache(location string) *cachedDocument {
+	if f.cacheDir == "" {
+		return nil
+	}
+	content, err := os.ReadFile(f.cachePath(location))
+	if err != nil {
+		return nil
+	}
+	var cached cachedDocument
+	if err := json.Unmarshal(content, &cached); err != nil || cached.URL != location {
+		return nil
+	}
+	return &cached
+}
+
+// Stores a document in the cache, a cache that can't be written only costs the offline fallback
+func (f *specFetcher) writeCache(document cachedDocument) {
+	if f.cacheDir == "" {
+		return
+	}
+	if err := writeCacheFile(f.cachePath(document.URL), document); err != nil {
+		f.config.warn("", "failed to cache %s: %v", document.URL, err)
+	}
+}
+
+func writeCacheFile(cachePath string, document cachedDocument) error {
+	content, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		return err
+	}
+
+	// the copy is written next to the cache file and moved into place, so a crash never leaves half a spec behind
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), ".spec-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), cachePath)
+}
@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Serves specs that need a token, with ETags so they can be revalidated
+func specServer(t *testing.T, documents map[string]string) (*httptest.Server, *int32) {
+	t.Helper()
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer spec-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		document, ok := documents[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		atomic.AddInt32(&fetches, 1)
+		etag := `"` + r.URL.Path + `-v1"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(document))
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetches
+}
+
+const remoteOpenAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "schemas/pet.yaml#/Pet"}}}},
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`
+
+const remotePetSchema = `
+Pet:
+  type: object
+  required: [name]
+  properties:
+    name:
+      type: string
+`
+
+func TestParseSpecRouter_URL(t *testing.T) {
+	server, _ := specServer(t, map[string]string{
+		"/specs/pets.json":        remoteOpenAPISpec,
+		"/specs/schemas/pet.yaml": remotePetSchema,
+	})
+
+	_, err := ParseSpecRouter(server.URL + "/specs/pets.json")
+	assert.ErrorContains(t, err, "401 Unauthorized")
+
+	tools, err := ParseSpecRouter(server.URL+"/specs/pets.json", WithSpecHeaders(map[string]string{"Authorization": "Bearer spec-token"}))
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	// the server of the spec is the URL it was loaded from
+	assert.Equal(t, server.URL+"/pets", tools[0].Operation.URL)
+	body := tools[0].InputSchema.Properties["body"].(map[string]interface{})
+	assert.Contains(t, body["properties"], "name")
+}
+
+func TestParseSpecRouter_URLSwagger(t *testing.T) {
+	server, _ := specServer(t, map[string]string{
+		"/swagger.json": `{
+  "swagger": "2.0",
+  "info": {"title": "pets", "version": "1"},
+  "host": "pets.example.com",
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [{"$ref": "parameters.json#/limit"}],
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`,
+		"/parameters.json": `{"limit": {"name": "limit", "in": "query", "type": "integer"}}`,
+	})
+
+	tools, err := ParseSpecRouter(server.URL+"/swagger.json", WithSpecHeaders(map[string]string{"Authorization": "Bearer spec-token"}))
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Contains(t, tools[0].InputSchema.Properties, "limit")
+}
+
+func TestParseSpecRouter_SpecCache(t *testing.T) {
+	server, fetches := specServer(t, map[string]string{
+		"/specs/pets.json":        remoteOpenAPISpec,
+		"/specs/schemas/pet.yaml": remotePetSchema,
+	})
+	cacheDir := t.TempDir()
+	specURL := server.URL + "/specs/pets.json"
+	opts := []ParseOption{
+		WithSpecHeaders(map[string]string{"Authorization": "Bearer spec-token"}),
+		WithSpecCache(cacheDir),
+		WithBaseURL("https://pets.example.com"),
+	}
+
+	_, err := ParseSpecRouter(specURL, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(fetches), "the spec is fetched once even though it is read twice")
+
+	cached, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, cached, 2)
+
+	// the cached copies are revalidated
+	_, err = ParseSpecRouter(specURL, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(fetches))
+
+	// and used when the server is gone
+	server.Close()
+	var diagnostics Diagnostics
+	tools, err := ParseSpecRouter(specURL, append(opts, WithDiagnostics(&diagnostics))...)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	require.NotEmpty(t, diagnostics)
+	assert.Contains(t, diagnostics[0].Message, "using the cached copy")
+
+	_, err = ParseSpecRouter(specURL, opts[0], WithSpecCache(t.TempDir()))
+	assert.Error(t, err)
+}
+
+func TestParseSpecRouter_RefOutsideSpec(t *testing.T) {
+	root := t.TempDir()
+	writeSpec(t, root, "pet.json", `{"type": "object"}`)
+	specDir := filepath.Join(root, "specs")
+	require.NoError(t, os.Mkdir(specDir, 0755))
+	specPath := writeSpec(t, specDir, "pets.json", `{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "../pet.json"}}}},
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`)
+
+	_, err := ParseSpecRouter(specPath)
+	assert.ErrorContains(t, err, "is not allowed, a local spec may only reference files in "+specDir)
+}
+
+func TestParseSpecRouter_Sources(t *testing.T) {
+	dir := t.TempDir()
+	petPath := writeSpec(t, dir, "pet.json", `{"type": "object"}`)
+	specPath := writeSpec(t, dir, "pets.json", `{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "servers": [{"url": "https://pets.example.com"}],
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "pet.json"}}}},
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`)
+
+	var sources []string
+	_, err := ParseSpecRouter(specPath, WithSources(&sources))
+	require.NoError(t, err)
+	assert.Equal(t, []string{specPath, petPath}, sources)
+
+	server, _ := specServer(t, map[string]string{
+		"/specs/pets.json":        remoteOpenAPISpec,
+		"/specs/schemas/pet.yaml": remotePetSchema,
+	})
+	sources = nil
+	_, err = ParseSpecRouter(server.URL+"/specs/pets.json", WithSources(&sources), WithSpecHeaders(map[string]string{"Authorization": "Bearer spec-token"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{server.URL + "/specs/pets.json", server.URL + "/specs/schemas/pet.yaml"}, sources)
+}
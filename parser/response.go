@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+)
+
+// ResponseDefault is the key of the response that covers every status the spec doesn't list
+const ResponseDefault = "default"
+
+// Response is what the spec documents for a response status
+type Response struct {
+	Description string
+	// Schema is the JSON Schema of the JSON body, nil when the spec doesn't describe one
+	Schema map[string]interface{}
+}
+
+// ResponseFor finds the documented response for a status code
+// An exact status wins over a range like 2XX, which wins over the default response
+func (o Operation) ResponseFor(status int) (Response, bool) {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, fmt.Sprintf("%cXX", code[0]), ResponseDefault} {
+		if response, ok := o.Responses[key]; ok {
+			return response, true
+		}
+	}
+	return Response{}, false
+}
+
+// Converts the documented responses of an OpenAPI operation
+func convertOpenAPIResponses(responses *openapi3.Responses) map[string]Response {
+	if responses == nil || responses.Len() == 0 {
+		return nil
+	}
+
+	result := make(map[string]Response, responses.Len())
+	for status, ref := range responses.Map() {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+
+		var response Response
+		if ref.Value.Description != nil {
+			response.Description = *ref.Value.Description
+		}
+		for _, mediaType := range sortedKeys(ref.Value.Content) {
+			content := ref.Value.Content[mediaType]
+			if bodyEncoding(mediaType) != BodyEncodingJSON || content == nil || content.Schema == nil {
+				continue
+			}
+			// every response gets its own converter so its schema carries its own $defs
+			converter := newSchemaConverter(directionResponse)
+			response.Schema = converter.convertRef(content.Schema)
+			if len(converter.defs) > 0 {
+				response.Schema["$defs"] = converter.defs
+			}
+			break
+		}
+
+		result[status] = response
+	}
+	return result
+}
+
+// Converts the documented responses of a Swagger operation, whose bodies are JSON unless it says otherwise
+func convertSwaggerResponses(root *spec.Swagger, responses *spec.Responses, produces []string) map[string]Response {
+	if responses == nil {
+		return nil
+	}
+
+	json := len(produces) == 0
+	for _, mediaType := range produces {
+		if bodyEncoding(mediaType) == BodyEncodingJSON {
+			json = true
+		}
+	}
+
+	convert := func(response spec.Response) Response {
+		result := Response{Description: response.Description}
+		if json && response.Schema != nil {
+			// every response gets its own converter so its schema carries its own $defs
+			result.Schema = convertSchemaToMap(root, response.Schema, directionResponse)
+		}
+		return result
+	}
+
+	result := make(map[string]Response, len(responses.StatusCodeResponses)+1)
+	for status, response := range responses.StatusCodeResponses {
+		result[strconv.Itoa(status)] = convert(response)
+	}
+	if responses.Default != nil {
+		result[ResponseDefault] = convert(*responses.Default)
+	}
+	return result
+}
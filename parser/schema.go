@@ -0,0 +1,339 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var invalidDefNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// Guards against inline schemas that were wired into a cycle without a $ref
+const maxSchemaDepth = 64
+
+// Which way the values of a schema travel, a property that never travels that way can't be required
+type schemaDirection int
+
+const (
+	// the schema is converted as written
+	directionAny schemaDirection = iota
+	// the schema describes a request, readOnly properties are never sent
+	directionRequest
+	// the schema describes a response, writeOnly properties are never returned
+	directionResponse
+)
+
+// Converts OpenAPI schemas to draft 2020-12 JSON Schema
+// Schemas that reference themselves are emitted once under $defs and referenced from everywhere else,
+// so a single converter should be shared by all the schemas that end up in the same tool
+type schemaConverter struct {
+	direction schemaDirection
+	defs      map[string]interface{}
+	visiting  map[*openapi3.Schema]string
+	recursive map[*openapi3.Schema]string
+	taken     map[string]bool
+	depth     int
+}
+
+func newSchemaConverter(direction schemaDirection) *schemaConverter {
+	return &schemaConverter{
+		direction: direction,
+		defs:      make(map[string]interface{}),
+		visiting:  make(map[*openapi3.Schema]string),
+		recursive: make(map[*openapi3.Schema]string),
+		taken:     make(map[string]bool),
+	}
+}
+
+// ConvertSchemaToMap converts an OpenAPI schema to a standalone JSON Schema
+// Recursive schemas are placed under $defs in the returned map
+func ConvertSchemaToMap(schema *openapi3.Schema) map[string]interface{} {
+	converter := newSchemaConverter(directionAny)
+	result := converter.convert(schema, "")
+	if len(converter.defs) > 0 {
+		result["$defs"] = converter.defs
+	}
+	return result
+}
+
+func (c *schemaConverter) convertRef(ref *openapi3.SchemaRef) map[string]interface{} {
+	if ref == nil {
+		return map[string]interface{}{}
+	}
+	return c.convert(ref.Value, ref.Ref)
+}
+
+func (c *schemaConverter) convert(schema *openapi3.Schema, ref string) map[string]interface{} {
+	// an absent schema places no constraints on the value
+	if schema == nil {
+		return map[string]interface{}{}
+	}
+
+	// Inline schemas can only loop back on themselves through a $ref, so only those need tracking
+	if ref == "" {
+		if c.depth >= maxSchemaDepth {
+			return map[string]interface{}{}
+		}
+		c.depth++
+		defer func() { c.depth-- }()
+		return c.convertSchema(schema)
+	}
+
+	if name, ok := c.recursive[schema]; ok {
+		return defRef(name)
+	}
+
+	// We're already inside this schema so it's recursive, point back at it instead of descending again
+	if name, ok := c.visiting[schema]; ok {
+		c.recursive[schema] = name
+		return defRef(name)
+	}
+
+	name := c.defName(ref)
+	c.visiting[schema] = name
+	result := c.convertSchema(schema)
+	delete(c.visiting, schema)
+
+	if _, ok := c.recursive[schema]; ok {
+		c.defs[name] = result
+		return defRef(name)
+	}
+
+	// the name was only reserved in case the schema turned out to be recursive
+	delete(c.taken, name)
+	return result
+}
+
+func (c *schemaConverter) convertSchema(schema *openapi3.Schema) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	// Handle basic properties
+	types := schema.Type.Slice()
+	if schema.Nullable && len(types) > 0 && !containsString(types, "null") {
+		types = append(append([]string{}, types...), "null")
+	}
+	if len(types) == 1 {
+		result["type"] = types[0]
+	} else if len(types) > 1 {
+		result["type"] = types
+	}
+	if schema.Title != "" {
+		result["title"] = schema.Title
+	}
+	if schema.Format != "" {
+		result["format"] = schema.Format
+	}
+	if schema.Description != "" {
+		result["description"] = schema.Description
+	}
+	if len(schema.Enum) > 0 {
+		enum := schema.Enum
+		if schema.Nullable && !containsNil(enum) {
+			enum = append(append([]interface{}{}, enum...), nil)
+		}
+		result["enum"] = enum
+	}
+	if schema.Default != nil {
+		result["default"] = schema.Default
+	}
+	if schema.Example != nil {
+		result["examples"] = []interface{}{schema.Example}
+	}
+	if schema.ReadOnly {
+		result["readOnly"] = true
+	}
+	if schema.WriteOnly {
+		result["writeOnly"] = true
+	}
+	if schema.Deprecated {
+		result["deprecated"] = true
+	}
+
+	// Handle composition
+	if len(schema.AllOf) > 0 {
+		result["allOf"] = c.convertRefs(schema.AllOf)
+	}
+	if len(schema.OneOf) > 0 {
+		result["oneOf"] = c.convertRefs(schema.OneOf)
+	}
+	if len(schema.AnyOf) > 0 {
+		result["anyOf"] = c.convertRefs(schema.AnyOf)
+	}
+	if schema.Not != nil {
+		result["not"] = c.convertRef(schema.Not)
+	}
+
+	// Handle array type
+	if schema.Items != nil {
+		result["items"] = c.convertRef(schema.Items)
+	}
+	if schema.MinItems != 0 {
+		result["minItems"] = schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		result["maxItems"] = *schema.MaxItems
+	}
+	if schema.UniqueItems {
+		result["uniqueItems"] = true
+	}
+
+	// Handle object properties
+	if len(schema.Properties) > 0 {
+		props := make(map[string]interface{})
+		for name, prop := range schema.Properties {
+			props[name] = c.convertRef(prop)
+		}
+		result["properties"] = props
+	}
+	if required := c.required(schema); len(required) > 0 {
+		result["required"] = required
+	}
+	if schema.AdditionalProperties.Schema != nil {
+		result["additionalProperties"] = c.convertRef(schema.AdditionalProperties.Schema)
+	} else if schema.AdditionalProperties.Has != nil {
+		result["additionalProperties"] = *schema.AdditionalProperties.Has
+	}
+	if schema.MinProps != 0 {
+		result["minProperties"] = schema.MinProps
+	}
+	if schema.MaxProps != nil {
+		result["maxProperties"] = *schema.MaxProps
+	}
+
+	// Handle constraints
+	// OpenAPI 3.0 exclusive bounds are flags on minimum/maximum, JSON Schema uses the bound itself
+	if schema.Min != nil {
+		if schema.ExclusiveMin {
+			result["exclusiveMinimum"] = *schema.Min
+		} else {
+			result["minimum"] = *schema.Min
+		}
+	}
+	if schema.Max != nil {
+		if schema.ExclusiveMax {
+			result["exclusiveMaximum"] = *schema.Max
+		} else {
+			result["maximum"] = *schema.Max
+		}
+	}
+	if schema.MultipleOf != nil {
+		result["multipleOf"] = *schema.MultipleOf
+	}
+	if schema.MinLength != 0 {
+		result["minLength"] = schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		result["maxLength"] = *schema.MaxLength
+	}
+	if schema.Pattern != "" {
+		result["pattern"] = schema.Pattern
+	}
+
+	// OpenAPI 3.1 schemas are JSON Schema, the keywords kin-openapi doesn't model end up in the extensions
+	for _, keyword := range jsonSchemaKeywords {
+		if value, ok := schema.Extensions[keyword]; ok {
+			result[keyword] = value
+		}
+	}
+
+	// Without a type to add null to, nullable lets null through next to the schema
+	if schema.Nullable && len(types) == 0 {
+		return allowNull(result)
+	}
+
+	return result
+}
+
+// Leaves the properties that never travel in the converter's direction out of the required ones
+func (c *schemaConverter) required(schema *openapi3.Schema) []string {
+	if c.direction == directionAny {
+		return schema.Required
+	}
+
+	required := make([]string, 0, len(schema.Required))
+	for _, name := range schema.Required {
+		prop := schema.Properties[name]
+		if prop != nil && prop.Value != nil && c.direction.omits(prop.Value.ReadOnly, prop.Value.WriteOnly) {
+			continue
+		}
+		required = append(required, name)
+	}
+	return required
+}
+
+// Whether a property with the given readOnly and writeOnly flags is never part of a value going this way
+func (d schemaDirection) omits(readOnly bool, writeOnly bool) bool {
+	return (d == directionRequest && readOnly) || (d == directionResponse && writeOnly)
+}
+
+func (c *schemaConverter) convertRefs(refs openapi3.SchemaRefs) []interface{} {
+	result := make([]interface{}, 0, len(refs))
+	for _, ref := range refs {
+		result = append(result, c.convertRef(ref))
+	}
+	return result
+}
+
+func (c *schemaConverter) defName(ref string) string {
+	return uniqueDefName(c.taken, ref)
+}
+
+// Picks a unique $defs name based on the last segment of the schema's $ref and reserves it
+func uniqueDefName(taken map[string]bool, ref string) string {
+	base := ref[strings.LastIndex(ref, "/")+1:]
+	base = invalidDefNameChars.ReplaceAllString(base, "_")
+	if base == "" {
+		base = "schema"
+	}
+
+	name := base
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	taken[name] = true
+	return name
+}
+
+func defRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/$defs/" + name}
+}
+
+// Keywords that describe a value rather than constrain it, kept on the outside when a schema is wrapped
+var annotationKeywords = []string{"title", "description", "default", "examples", "deprecated", "readOnly", "writeOnly"}
+
+// Wraps a schema in an anyOf that also accepts null
+func allowNull(schema map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, keyword := range annotationKeywords {
+		if value, ok := schema[keyword]; ok {
+			result[keyword] = value
+			delete(schema, keyword)
+		}
+	}
+	// a schema with nothing but annotations already accepts null
+	if len(schema) == 0 {
+		return result
+	}
+	result["anyOf"] = []interface{}{schema, map[string]interface{}{"type": "null"}}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsNil(values []interface{}) bool {
+	for _, v := range values {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertSchemaToMap(t *testing.T) {
+	min := 0.0
+	max := 10.0
+	tests := []struct {
+		name     string
+		schema   *openapi3.Schema
+		expected string
+	}{
+		{
+			name:     "nil schema",
+			schema:   nil,
+			expected: `{}`,
+		},
+		{
+			name:     "integer keeps its type",
+			schema:   &openapi3.Schema{Type: &openapi3.Types{"integer"}, Format: "int64"},
+			expected: `{"type": "integer", "format": "int64"}`,
+		},
+		{
+			name:     "nullable string",
+			schema:   &openapi3.Schema{Type: &openapi3.Types{"string"}, Nullable: true, Enum: []interface{}{"a", "b"}},
+			expected: `{"type": ["string", "null"], "enum": ["a", "b", null]}`,
+		},
+		{
+			name: "nullable without a type",
+			schema: &openapi3.Schema{
+				Nullable:    true,
+				Description: "The owner",
+				AllOf:       openapi3.SchemaRefs{openapi3.NewSchemaRef("", openapi3.NewObjectSchema())},
+			},
+			expected: `{"description": "The owner", "anyOf": [{"allOf": [{"type": "object"}]}, {"type": "null"}]}`,
+		},
+		{
+			name:     "nullable without constraints",
+			schema:   &openapi3.Schema{Nullable: true, Description: "Anything"},
+			expected: `{"description": "Anything"}`,
+		},
+		{
+			name:     "exclusive bounds",
+			schema:   &openapi3.Schema{Type: &openapi3.Types{"number"}, Min: &min, Max: &max, ExclusiveMin: true},
+			expected: `{"type": "number", "exclusiveMinimum": 0, "maximum": 10}`,
+		},
+		{
+			name: "annotations",
+			schema: &openapi3.Schema{
+				Type:      &openapi3.Types{"string"},
+				Default:   "x",
+				Example:   "y",
+				ReadOnly:  true,
+				WriteOnly: false,
+			},
+			expected: `{"type": "string", "default": "x", "examples": ["y"], "readOnly": true}`,
+		},
+		{
+			name: "composition",
+			schema: &openapi3.Schema{
+				AllOf: openapi3.SchemaRefs{openapi3.NewSchemaRef("", openapi3.NewStringSchema())},
+				OneOf: openapi3.SchemaRefs{openapi3.NewSchemaRef("", openapi3.NewIntegerSchema())},
+				AnyOf: openapi3.SchemaRefs{openapi3.NewSchemaRef("", openapi3.NewBoolSchema())},
+				Not:   openapi3.NewSchemaRef("", openapi3.NewFloat64Schema()),
+			},
+			expected: `{
+				"allOf": [{"type": "string"}],
+				"oneOf": [{"type": "integer"}],
+				"anyOf": [{"type": "boolean"}],
+				"not": {"type": "number"}
+			}`,
+		},
+		{
+			name: "additional properties",
+			schema: &openapi3.Schema{
+				Type: &openapi3.Types{"object"},
+				AdditionalProperties: openapi3.AdditionalProperties{
+					Schema: openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+				},
+			},
+			expected: `{"type": "object", "additionalProperties": {"type": "string"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := json.Marshal(ConvertSchemaToMap(tt.schema))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(actual))
+		})
+	}
+}
+
+func TestConvertSchemaToMap_RecursiveRef(t *testing.T) {
+	spec := []byte(`{
+		"openapi": "3.0.3",
+		"info": {"title": "tree", "version": "1"},
+		"paths": {},
+		"components": {
+			"schemas": {
+				"Node": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"children": {"type": "array", "items": {"$ref": "#/components/schemas/Node"}}
+					}
+				}
+			}
+		}
+	}`)
+
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	require.NoError(t, err)
+
+	actual, err := json.Marshal(ConvertSchemaToMap(doc.Components.Schemas["Node"].Value))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"children": {"type": "array", "items": {"$ref": "#/$defs/Node"}}
+		},
+		"$defs": {
+			"Node": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"},
+					"children": {"type": "array", "items": {"$ref": "#/$defs/Node"}}
+				}
+			}
+		}
+	}`, string(actual))
+
+	converter := newSchemaConverter(directionAny)
+	converted := converter.convertRef(doc.Components.Schemas["Node"].Value.Properties["children"])
+	assert.Equal(t, map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/$defs/Node"}}, converted)
+	assert.Contains(t, converter.defs, "Node")
+}
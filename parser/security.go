@@ -0,0 +1,199 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+)
+
+// Security scheme types as defined by the OpenAPI spec
+// Swagger's basic type is mapped onto http with the basic scheme
+const (
+	SecurityTypeAPIKey        = "apiKey"
+	SecurityTypeHTTP          = "http"
+	SecurityTypeOAuth2        = "oauth2"
+	SecurityTypeOpenIDConnect = "openIdConnect"
+)
+
+// SecurityScheme describes how credentials are attached to a request
+// Name is the key the scheme is declared under in the spec and is what credentials are looked up by
+type SecurityScheme struct {
+	Name string
+	Type string
+	// Location (header, query or cookie) and parameter name of an API key
+	In        string
+	ParamName string
+	// HTTP authentication scheme, e.g. basic or bearer
+	Scheme string
+	// Token endpoint of the OAuth2 client credentials flow
+	TokenURL string
+	Scopes   []string
+}
+
+// SecurityRequirement lists the schemes that all have to be applied to a request
+// An operation is authorized when any one of its requirements is satisfied
+type SecurityRequirement []SecurityScheme
+
+// Resolves the security requirements of an OpenAPI operation, falling back to the top level requirements of the spec
+func openAPISecurity(doc *openapi3.T, operation *openapi3.Operation, config *parseConfig, operationPointer string) ([]SecurityRequirement, error) {
+	requirements := doc.Security
+	pointer := "/security"
+	if operation.Security != nil {
+		requirements = *operation.Security
+		pointer = operationPointer + "/security"
+	}
+	if len(requirements) == 0 {
+		return nil, nil
+	}
+
+	var result []SecurityRequirement
+	for i, requirement := range requirements {
+		var schemes SecurityRequirement
+		for _, name := range sortedKeys(requirement) {
+			var ref *openapi3.SecuritySchemeRef
+			if doc.Components != nil {
+				ref = doc.Components.SecuritySchemes[name]
+			}
+			if ref == nil || ref.Value == nil {
+				scheme, err := undefinedSecurityScheme(config, fmt.Sprintf("%s/%d", pointer, i), name, "components.securitySchemes")
+				if err != nil {
+					return nil, err
+				}
+				schemes = append(schemes, scheme)
+				continue
+			}
+			schemes = append(schemes, newOpenAPISecurityScheme(name, ref.Value, requirement[name]))
+		}
+		result = append(result, schemes)
+	}
+
+	return result, nil
+}
+
+func newOpenAPISecurityScheme(name string, scheme *openapi3.SecurityScheme, scopes []string) SecurityScheme {
+	result := SecurityScheme{
+		Name:      name,
+		Type:      scheme.Type,
+		In:        scheme.In,
+		ParamName: scheme.Name,
+		Scheme:    strings.ToLower(scheme.Scheme),
+		Scopes:    scopes,
+	}
+
+	if scheme.Flows != nil && scheme.Flows.ClientCredentials != nil {
+		result.TokenURL = scheme.Flows.ClientCredentials.TokenURL
+	}
+
+	return result
+}
+
+// Resolves the security requirements of a Swagger operation, falling back to the top level requirements of the spec
+func swaggerSecurity(doc *spec.Swagger, operation *spec.Operation, config *parseConfig, operationPointer string) ([]SecurityRequirement, error) {
+	requirements := doc.Security
+	pointer := "/security"
+	if operation.Security != nil {
+		requirements = operation.Security
+		pointer = operationPointer + "/security"
+	}
+	if len(requirements) == 0 {
+		return nil, nil
+	}
+
+	var result []SecurityRequirement
+	for i, requirement := range requirements {
+		var schemes SecurityRequirement
+		for _, name := range sortedKeys(requirement) {
+			scheme, ok := doc.SecurityDefinitions[name]
+			if !ok || scheme == nil {
+				undefined, err := undefinedSecurityScheme(config, fmt.Sprintf("%s/%d", pointer, i), name, "securityDefinitions")
+				if err != nil {
+					return nil, err
+				}
+				schemes = append(schemes, undefined)
+				continue
+			}
+			schemes = append(schemes, newSwaggerSecurityScheme(name, scheme, requirement[name]))
+		}
+		result = append(result, schemes)
+	}
+
+	return result, nil
+}
+
+// Handles a security requirement naming a scheme the spec doesn't define, an error unless loading leniently
+// Leaving the scheme out would make the requirement empty, which counts as satisfied and sends the request without
+// credentials, so the scheme is kept without a type and no credentials can satisfy it
+func undefinedSecurityScheme(config *parseConfig, pointer string, name string, section string) (SecurityScheme, error) {
+	if !config.lenient {
+		return SecurityScheme{}, fmt.Errorf("security scheme %q is not defined in %s", name, section)
+	}
+	config.fail(pointer, "security scheme %q is not defined in %s, requests that need it can't be authorized", name, section)
+	return SecurityScheme{Name: name}, nil
+}
+
+func newSwaggerSecurityScheme(name string, scheme *spec.SecurityScheme, scopes []string) SecurityScheme {
+	result := SecurityScheme{
+		Name:      name,
+		Type:      scheme.Type,
+		In:        scheme.In,
+		ParamName: scheme.Name,
+		Scopes:    scopes,
+	}
+
+	switch scheme.Type {
+	case "basic":
+		result.Type = SecurityTypeHTTP
+		result.Scheme = "basic"
+	case SecurityTypeOAuth2:
+		if scheme.Flow == "application" {
+			result.TokenURL = scheme.TokenURL
+		}
+	}
+
+	return result
+}
+
+// Removes parameters that carry an API key from the tool so the model is never asked to supply credentials
+func hideCredentialParameters(tool *Tool) {
+	credentials := make(map[string]bool)
+	for _, requirement := range tool.Operation.Security {
+		for _, scheme := range requirement {
+			if scheme.Type == SecurityTypeAPIKey {
+				credentials[scheme.In+":"+strings.ToLower(scheme.ParamName)] = true
+			}
+		}
+	}
+	if len(credentials) == 0 {
+		return
+	}
+
+	var parameters []Parameter
+	for _, param := range tool.Operation.Parameters {
+		if !credentials[param.In+":"+strings.ToLower(param.Name)] {
+			parameters = append(parameters, param)
+			continue
+		}
+
+		delete(tool.InputSchema.Properties, param.ArgumentName())
+		var required []string
+		for _, name := range tool.InputSchema.Required {
+			if name != param.ArgumentName() {
+				required = append(required, name)
+			}
+		}
+		tool.InputSchema.Required = required
+	}
+	tool.Operation.Parameters = parameters
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
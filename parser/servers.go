@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var serverVariablePattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Resolves the base URL of an operation
+// Servers declared on the operation win over the ones on its path, which win over the ones on the spec
+func resolveOperationServer(
+	config *parseConfig,
+	specServers openapi3.Servers,
+	pathServers openapi3.Servers,
+	operation *openapi3.Operation,
+) (string, error) {
+	if config.baseURL != "" {
+		return config.baseURL, nil
+	}
+	if operation.Servers != nil && len(*operation.Servers) > 0 {
+		return resolveServerURL(config, *operation.Servers, true)
+	}
+	if len(pathServers) > 0 {
+		return resolveServerURL(config, pathServers, true)
+	}
+	return resolveServerURL(config, specServers, false)
+}
+
+// Picks a server from the list and expands it into a base URL
+// Overrides on a path or operation often only list a single server, so the selection falls back to
+// the first one there instead of failing
+func resolveServerURL(config *parseConfig, servers openapi3.Servers, override bool) (string, error) {
+	// the spec says a missing servers list means the API lives at the root of wherever the spec is served from
+	if len(servers) == 0 {
+		return resolveRelativeURL(config.specLocation, "/")
+	}
+
+	server, err := selectServer(config, servers)
+	if err != nil {
+		if !override {
+			return "", err
+		}
+		server = servers[0]
+	}
+
+	serverURL, err := expandServerVariables(server, config.serverVariables)
+	if err != nil {
+		return "", err
+	}
+
+	return resolveRelativeURL(config.specLocation, serverURL)
+}
+
+func selectServer(config *parseConfig, servers openapi3.Servers) (*openapi3.Server, error) {
+	if config.serverIndex >= 0 {
+		if config.serverIndex >= len(servers) {
+			return nil, fmt.Errorf("server index %d is out of range, the spec declares %d servers: %s", config.serverIndex, len(servers), describeServers(servers))
+		}
+		return servers[config.serverIndex], nil
+	}
+
+	if config.serverDescription != "" {
+		selector := strings.ToLower(config.serverDescription)
+		for _, server := range servers {
+			if strings.Contains(strings.ToLower(server.Description), selector) {
+				return server, nil
+			}
+		}
+		return nil, fmt.Errorf("no server matches %q, the spec declares: %s", config.serverDescription, describeServers(servers))
+	}
+
+	return servers[0], nil
+}
+
+// Substitutes the {variable} placeholders of a server URL with the overrides or their defaults
+func expandServerVariables(server *openapi3.Server, overrides map[string]string) (string, error) {
+	var expandErr error
+	expanded := serverVariablePattern.ReplaceAllStringFunc(server.URL, func(match string) string {
+		name := match[1 : len(match)-1]
+		variable := server.Variables[name]
+
+		value, ok := overrides[name]
+		if !ok {
+			if variable == nil {
+				expandErr = fmt.Errorf("server %s uses variable %s which has no definition or override", server.URL, name)
+				return match
+			}
+			return variable.Default
+		}
+
+		if variable != nil && len(variable.Enum) > 0 && !containsString(variable.Enum, value) {
+			expandErr = fmt.Errorf("value %q for server variable %s must be one of %s", value, name, strings.Join(variable.Enum, ", "))
+		}
+		return value
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+
+	return strings.TrimSuffix(expanded, "/"), nil
+}
+
+// Resolves a relative server URL against the location of a spec that was loaded from a URL
+// Relative URLs can't be resolved for specs loaded from disk, requests to them would fail once they are sent
+// Without a location, e.g. for a document converted directly, they are left for the caller to resolve
+func resolveRelativeURL(specLocation string, serverURL string) (string, error) {
+	parsed, err := url.Parse(serverURL)
+	if err != nil || parsed.IsAbs() || specLocation == "" {
+		return strings.TrimSuffix(serverURL, "/"), nil
+	}
+
+	base, err := url.Parse(specLocation)
+	if !IsURL(specLocation) || err != nil {
+		return "", errRelativeServer(serverURL)
+	}
+
+	return strings.TrimSuffix(base.ResolveReference(parsed).String(), "/"), nil
+}
+
+// Explains that a spec read from disk needs a base URL, because the server it names can't be resolved
+func errRelativeServer(serverURL string) error {
+	if serverURL == "" || serverURL == "/" {
+		return fmt.Errorf("the spec declares no server and wasn't loaded from a URL, pass --base-url or set base_url in the config file")
+	}
+	return fmt.Errorf("server URL %s is relative and the spec wasn't loaded from a URL to resolve it against, pass --base-url or set base_url in the config file", serverURL)
+}
+
+func describeServers(servers openapi3.Servers) string {
+	descriptions := make([]string, 0, len(servers))
+	for i, server := range servers {
+		if server.Description != "" {
+			descriptions = append(descriptions, fmt.Sprintf("%d: %s (%s)", i, server.URL, server.Description))
+		} else {
+			descriptions = append(descriptions, fmt.Sprintf("%d: %s", i, server.URL))
+		}
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serversSpec = `{
+	"openapi": "3.0.3",
+	"info": {"title": "servers", "version": "1"},
+	"servers": [
+		{"url": "https://api.example.com/v1", "description": "Production server"},
+		{
+			"url": "https://{region}.staging.example.com/{basePath}",
+			"description": "Staging server",
+			"variables": {
+				"region": {"default": "us", "enum": ["us", "eu"]},
+				"basePath": {"default": "v2"}
+			}
+		}
+	],
+	"paths": {
+		"/pets": {
+			"get": {"operationId": "listPets", "responses": {"200": {"description": "ok"}}},
+			"post": {
+				"operationId": "createPet",
+				"servers": [{"url": "https://write.example.com"}],
+				"responses": {"200": {"description": "ok"}}
+			}
+		},
+		"/files": {
+			"servers": [{"url": "/uploads"}],
+			"get": {"operationId": "listFiles", "responses": {"200": {"description": "ok"}}}
+		}
+	}
+}`
+
+func convertServersSpec(t *testing.T, opts ...ParseOption) (map[string]string, error) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(serversSpec))
+	require.NoError(t, err)
+
+	tools, err := ConvertOpenAPIToMCPTools(doc, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make(map[string]string)
+	for _, tool := range tools {
+		urls[tool.Name] = tool.Operation.URL
+	}
+	return urls, nil
+}
+
+func TestServerResolution(t *testing.T) {
+	t.Run("defaults to the first server", func(t *testing.T) {
+		urls, err := convertServersSpec(t)
+		require.NoError(t, err)
+		assert.Equal(t, "https://api.example.com/v1/pets", urls["listPets"])
+		assert.Equal(t, "https://write.example.com/pets", urls["createPet"])
+		assert.Equal(t, "/uploads/files", urls["listFiles"])
+	})
+
+	t.Run("selects by description and expands default variables", func(t *testing.T) {
+		urls, err := convertServersSpec(t, WithServer("staging"))
+		require.NoError(t, err)
+		assert.Equal(t, "https://us.staging.example.com/v2/pets", urls["listPets"])
+		assert.Equal(t, "https://write.example.com/pets", urls["createPet"])
+	})
+
+	t.Run("selects by index and applies variable overrides", func(t *testing.T) {
+		urls, err := convertServersSpec(t, WithServer("1"), WithServerVariables(map[string]string{"region": "eu"}))
+		require.NoError(t, err)
+		assert.Equal(t, "https://eu.staging.example.com/v2/pets", urls["listPets"])
+	})
+
+	t.Run("rejects overrides outside the enum", func(t *testing.T) {
+		_, err := convertServersSpec(t, WithServer("1"), WithServerVariables(map[string]string{"region": "ap"}))
+		assert.ErrorContains(t, err, "must be one of us, eu")
+	})
+
+	t.Run("rejects unknown servers", func(t *testing.T) {
+		_, err := convertServersSpec(t, WithServer("development"))
+		assert.ErrorContains(t, err, `no server matches "development"`)
+
+		_, err = convertServersSpec(t, WithServer("5"))
+		assert.ErrorContains(t, err, "server index 5 is out of range")
+	})
+
+	t.Run("resolves relative servers against the spec URL", func(t *testing.T) {
+		urls, err := convertServersSpec(t, WithSpecLocation("https://docs.example.com/specs/openapi.json"))
+		require.NoError(t, err)
+		assert.Equal(t, "https://docs.example.com/uploads/files", urls["listFiles"])
+	})
+}
+
+func TestServerResolution_NoServers(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(`{
+		"openapi": "3.0.3",
+		"info": {"title": "no servers", "version": "1"},
+		"paths": {"/pets": {"get": {"operationId": "listPets", "responses": {"200": {"description": "ok"}}}}}
+	}`))
+	require.NoError(t, err)
+
+	tools, err := ConvertOpenAPIToMCPTools(doc, WithSpecLocation("https://api.example.com/openapi.json"))
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "https://api.example.com/pets", tools[0].Operation.URL)
+}
+
+func TestServerResolution_LocalSpec(t *testing.T) {
+	dir := t.TempDir()
+	noServers := writeSpec(t, dir, "no-servers.yaml", `
+openapi: 3.0.3
+info: {title: no servers, version: "1"}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200": {description: ok}
+`)
+	relative := writeSpec(t, dir, "relative.yaml", `
+openapi: 3.0.3
+info: {title: relative, version: "1"}
+servers:
+  - url: /api/v3
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200": {description: ok}
+`)
+	swagger := writeSpec(t, dir, "swagger.yaml", `
+swagger: "2.0"
+info: {title: no host, version: "1"}
+basePath: /api/v3
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        200: {description: ok}
+`)
+
+	_, err := ParseSpecRouter(noServers)
+	assert.ErrorContains(t, err, "the spec declares no server and wasn't loaded from a URL, pass --base-url")
+	_, err = ParseSpecRouter(relative)
+	assert.ErrorContains(t, err, "server URL /api/v3 is relative and the spec wasn't loaded from a URL to resolve it against, pass --base-url")
+	_, err = ParseSpecRouter(swagger)
+	assert.ErrorContains(t, err, "server URL /api/v3 is relative and the spec wasn't loaded from a URL to resolve it against, pass --base-url")
+
+	var diagnostics Diagnostics
+	tools, err := ParseSpecRouter(relative, WithLenient(), WithDiagnostics(&diagnostics))
+	require.NoError(t, err)
+	assert.Empty(t, tools)
+	assert.Contains(t, diagnostics, Diagnostic{Severity: SeverityError, Pointer: "/paths/~1pets/get", Message: "left out, failed to resolve its server: server URL /api/v3 is relative and the spec wasn't loaded from a URL to resolve it against, pass --base-url or set base_url in the config file"})
+
+	diagnostics = nil
+	tools, err = ParseSpecRouter(swagger, WithLenient(), WithDiagnostics(&diagnostics))
+	require.NoError(t, err)
+	assert.Empty(t, tools)
+	assert.Contains(t, diagnostics, Diagnostic{Severity: SeverityError, Pointer: "/host", Message: "every operation is left out: server URL /api/v3 is relative and the spec wasn't loaded from a URL to resolve it against, pass --base-url or set base_url in the config file"})
+
+	for _, specPath := range []string{noServers, relative, swagger} {
+		tools, err := ParseSpecRouter(specPath, WithBaseURL("http://localhost:3001"))
+		require.NoError(t, err)
+		require.Len(t, tools, 1)
+		assert.Equal(t, "http://localhost:3001/pets", tools[0].Operation.URL)
+	}
+}
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/evisdrenova/axon-server/mcp"
 	"github.com/go-openapi/loads"
@@ -11,94 +14,190 @@ import (
 )
 
 // ConvertSwaggerToMCPTools converts a Swagger 2.0 spec to an array of MCP Tools
-func ConvertSwaggerToMCPTools(swaggerDoc *spec.Swagger) ([]mcp.Tool, error) {
-	var tools []mcp.Tool
+func ConvertSwaggerToMCPTools(swaggerDoc *spec.Swagger, opts ...ParseOption) ([]Tool, error) {
+	var tools []Tool
+	config := newParseConfig(opts)
 
-	// Extract base URL from the swagger doc
-	baseURL := swaggerDoc.BasePath
-	if swaggerDoc.Host != "" {
-		scheme := "https"
-		if len(swaggerDoc.Schemes) > 0 {
-			scheme = swaggerDoc.Schemes[0]
+	baseURL, err := swaggerBaseURL(swaggerDoc, config)
+	if err != nil {
+		if !config.lenient {
+			return nil, err
 		}
-		baseURL = fmt.Sprintf("%s://%s%s", scheme, swaggerDoc.Host, baseURL)
+		config.fail("/host", "every operation is left out: %v", err)
+		return nil, nil
 	}
 
-	// Iterate through all paths
-	for path, pathItem := range swaggerDoc.Paths.Paths {
-		fullPath := baseURL + path
+	type swaggerOperation struct {
+		id        string
+		path      string
+		method    string
+		pathItem  spec.PathItem
+		operation *spec.Operation
+	}
 
-		// Handle GET operations
-		if pathItem.Get != nil {
-			tool, err := convertOperationToMCPTool(pathItem.Get, "GET", fullPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert GET operation for %s: %w", fullPath, err)
-			}
-			if tool != nil {
-				tools = append(tools, *tool)
+	// Collect the operations in a stable order so synthesized names don't change between runs
+	var operations []swaggerOperation
+	var named []namedOperation
+	if swaggerDoc.Paths != nil {
+		for _, path := range sortedKeys(swaggerDoc.Paths.Paths) {
+			pathItem := swaggerDoc.Paths.Paths[path]
+
+			for _, op := range []struct {
+				method    string
+				operation *spec.Operation
+			}{
+				{"GET", pathItem.Get},
+				{"POST", pathItem.Post},
+				{"PUT", pathItem.Put},
+				{"DELETE", pathItem.Delete},
+				{"PATCH", pathItem.Patch},
+				{"HEAD", pathItem.Head},
+				{"OPTIONS", pathItem.Options},
+			} {
+				if op.operation == nil {
+					continue
+				}
+				pointer := operationPointer(path, op.method)
+				if extensionFlag(op.operation.Extensions, ExtensionHidden) {
+					config.warn(pointer, "left out, the operation is marked %s", ExtensionHidden)
+					continue
+				}
+
+				name := op.operation.ID
+				if name == "" {
+					name = synthesizeOperationName(op.method, path)
+				}
+				if reason := config.filter.rejects(filterCandidate{name, op.method, path, op.operation.Tags, op.operation.Deprecated}); reason != "" {
+					config.warn(pointer, "left out by the filter, %s", reason)
+					continue
+				}
+
+				operations = append(operations, swaggerOperation{name, path, op.method, pathItem, op.operation})
+				named = append(named, namedOperation{swaggerOperationName(op.operation), op.method, path})
 			}
 		}
+	}
 
-		// Handle POST operations
-		if pathItem.Post != nil {
-			tool, err := convertOperationToMCPTool(pathItem.Post, "POST", fullPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert POST operation for %s: %w", fullPath, err)
-			}
-			if tool != nil {
-				tools = append(tools, *tool)
-			}
-		}
+	names := assignToolNames(config, named)
+	for i, op := range operations {
+		fullPath := baseURL + op.path
 
-		// Handle PUT operations
-		if pathItem.Put != nil {
-			tool, err := convertOperationToMCPTool(pathItem.Put, "PUT", fullPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert PUT operation for %s: %w", fullPath, err)
-			}
-			if tool != nil {
-				tools = append(tools, *tool)
-			}
+		// parameters declared on the path apply to every operation unless the operation overrides them
+		operation := *op.operation
+		operation.Parameters = mergeSwaggerParameters(op.pathItem.Parameters, op.operation.Parameters)
+		if len(operation.Consumes) == 0 {
+			operation.Consumes = swaggerDoc.Consumes
+		}
+		if len(operation.Produces) == 0 {
+			operation.Produces = swaggerDoc.Produces
 		}
 
-		// Handle DELETE operations
-		if pathItem.Delete != nil {
-			tool, err := convertOperationToMCPTool(pathItem.Delete, "DELETE", fullPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert DELETE operation for %s: %w", fullPath, err)
-			}
-			if tool != nil {
-				tools = append(tools, *tool)
-			}
+		pointer := operationPointer(op.path, op.method)
+		for _, param := range operation.Parameters {
+			warnHiddenRequired(config, pointer, param.Name, param.Required, param.Extensions)
 		}
 
-		// Handle PATCH operations
-		if pathItem.Patch != nil {
-			tool, err := convertOperationToMCPTool(pathItem.Patch, "PATCH", fullPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert PATCH operation for %s: %w", fullPath, err)
-			}
-			if tool != nil {
-				tools = append(tools, *tool)
+		tool, err := convertOperationToMCPTool(swaggerDoc, &operation, op.method, fullPath)
+		if err != nil {
+			if config.lenient {
+				config.fail(pointer, "left out, failed to convert it: %v", err)
+				continue
 			}
+			return nil, fmt.Errorf("failed to convert %s operation for %s: %w", op.method, fullPath, err)
+		}
+		tool.Name = names[i]
+		tool.Operation.ID = op.id
+		security, err := swaggerSecurity(swaggerDoc, op.operation, config, pointer)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op.method, fullPath, err)
 		}
+		tool.Operation.Security = security
+		hideCredentialParameters(tool)
+		tools = append(tools, *tool)
 	}
 
 	return tools, nil
 }
 
+// The name a tool is derived from, x-mcp-name wins over the operationId
+func swaggerOperationName(operation *spec.Operation) string {
+	if name := extensionString(operation.Extensions, ExtensionName); name != "" {
+		return name
+	}
+	return operation.ID
+}
+
+// Merges path level parameters with the ones of an operation
+// An operation parameter with the same name and location replaces the path level one
+func mergeSwaggerParameters(pathParameters []spec.Parameter, operationParameters []spec.Parameter) []spec.Parameter {
+	if len(pathParameters) == 0 {
+		return operationParameters
+	}
+
+	overridden := make(map[string]bool)
+	for _, param := range operationParameters {
+		overridden[param.In+":"+param.Name] = true
+	}
+
+	merged := make([]spec.Parameter, 0, len(pathParameters)+len(operationParameters))
+	for _, param := range pathParameters {
+		if !overridden[param.In+":"+param.Name] {
+			merged = append(merged, param)
+		}
+	}
+	return append(merged, operationParameters...)
+}
+
+// Builds the base URL from the host, basePath and schemes of the spec
+// The spec says a missing host or scheme defaults to the one the spec itself was served from
+func swaggerBaseURL(swaggerDoc *spec.Swagger, config *parseConfig) (string, error) {
+	if config.baseURL != "" {
+		return config.baseURL, nil
+	}
+
+	var specURL *url.URL
+	if IsURL(config.specLocation) {
+		specURL, _ = url.Parse(config.specLocation)
+	}
+
+	host := swaggerDoc.Host
+	if host == "" && specURL != nil {
+		host = specURL.Host
+	}
+	if host == "" && config.specLocation == "" {
+		return strings.TrimSuffix(swaggerDoc.BasePath, "/"), nil
+	}
+	if host == "" {
+		return "", errRelativeServer(swaggerDoc.BasePath)
+	}
+
+	scheme := "https"
+	if len(swaggerDoc.Schemes) > 0 {
+		scheme = swaggerDoc.Schemes[0]
+		// a server index picks one of the declared schemes
+		if config.serverIndex >= 0 && config.serverIndex < len(swaggerDoc.Schemes) {
+			scheme = swaggerDoc.Schemes[config.serverIndex]
+		}
+	} else if specURL != nil {
+		scheme = specURL.Scheme
+	}
+
+	return strings.TrimSuffix(fmt.Sprintf("%s://%s%s", scheme, host, swaggerDoc.BasePath), "/"), nil
+}
+
+// Converts a single operation to a tool, $refs left in its schemas are resolved against the root spec
+// Operations without an operationId are named after their method and path
 func convertOperationToMCPTool(
+	root *spec.Swagger,
 	operation *spec.Operation,
 	method string,
 	path string,
-) (*mcp.Tool, error) {
-	if operation.ID == "" {
-		return nil, nil
-	}
-
+) (*Tool, error) {
 	// Create properties map for the tool schema
 	properties := make(map[string]interface{})
 	required := []string{}
+	var parameters []Parameter
+	converter := newSwaggerSchemaConverter(root, directionRequest)
 
 	// Add endpoint information
 	properties["endpoint"] = map[string]interface{}{
@@ -110,29 +209,121 @@ func convertOperationToMCPTool(
 		"const": method,
 	}
 
+	// formData parameters are collected into a single body argument
+	formProperties := make(map[string]interface{})
+	var formRequired []string
+	var fileFields []string
+	fixedFields := make(map[string]interface{})
+	var body *RequestBody
+
+	// form fields and the body become arguments of their own, only the other locations can share names
+	locations := make(parameterLocations)
+	for _, param := range operation.Parameters {
+		if param.Name != "" && param.In != "body" && param.In != "formData" {
+			locations.add(param.Name, param.In)
+		}
+	}
+
 	// Handle parameters
 	for _, param := range operation.Parameters {
 		if param.Name == "" {
 			continue
 		}
 
-		schema := convertSchemaToMap(param.Schema)
+		// Handle body parameter specifically
+		if param.In == "body" {
+			if param.Schema != nil {
+				var bodySchema map[string]interface{}
+				bodySchema, body = convertSwaggerBody(converter, param.Schema, operation.Consumes)
+				properties["body"] = bodySchema
+				if param.Required {
+					required = append(required, "body")
+				}
+			}
+			continue
+		}
+
+		extensions := readParameterExtensions(param.Extensions)
+
+		if param.In == "formData" {
+			if param.Type == "file" {
+				fileFields = append(fileFields, param.Name)
+			}
+			if extensions.fixed != nil {
+				fixedFields[param.Name] = extensions.fixed
+			}
+			if !extensions.visible(param.Required) {
+				continue
+			}
+
+			schema := convertSimpleSchemaToMap(&param.SimpleSchema, &param.CommonValidations)
+			if param.Type == "file" {
+				schema = fileSchema()
+			}
+			schema = withDescription(schema, param.Description)
+			extensions.apply(schema)
+			formProperties[param.Name] = schema
+			if param.Required {
+				formRequired = append(formRequired, param.Name)
+			}
+			continue
+		}
+
+		argument := locations.argument(param.Name, param.In)
+		switch param.In {
+		case ParameterInPath, ParameterInQuery, ParameterInHeader:
+			parameter := newSwaggerParameter(param.Name, param.In, param.CollectionFormat, param.Required)
+			parameter.Fixed = extensions.fixed
+			if argument != param.Name {
+				parameter.Argument = argument
+			}
+			parameters = append(parameters, parameter)
+		}
+
+		if !extensions.visible(param.Required) {
+			continue
+		}
+
+		schema := convertSimpleSchemaToMap(&param.SimpleSchema, &param.CommonValidations)
 		if param.Description != "" {
 			schema["description"] = param.Description
 		}
+		extensions.apply(schema)
 
-		properties[param.Name] = schema
+		properties[argument] = schema
 		if param.Required {
-			required = append(required, param.Name)
+			required = append(required, argument)
 		}
+	}
 
-		// Handle body parameter specifically
-		if param.In == "body" && param.Schema != nil {
-			properties["body"] = convertSchemaToMap(param.Schema)
-			if param.Required {
-				required = append(required, "body")
-			}
+	if len(formProperties) > 0 || len(fixedFields) > 0 {
+		// file parameters can only be sent as multipart
+		mediaType := "application/x-www-form-urlencoded"
+		if len(fileFields) > 0 || selectMediaType(operation.Consumes) == "multipart/form-data" {
+			mediaType = "multipart/form-data"
+		}
+		body = &RequestBody{
+			MediaType:  mediaType,
+			Encoding:   bodyEncoding(mediaType),
+			FileFields: fileFields,
+		}
+		if len(fixedFields) > 0 {
+			body.FixedFields = fixedFields
+		}
+	}
+
+	// a form whose fields are all fixed is sent without asking the model for a body
+	if len(formProperties) > 0 {
+		bodySchema := map[string]interface{}{
+			"type":       "object",
+			"properties": formProperties,
 		}
+		if len(formRequired) > 0 {
+			bodySchema["required"] = formRequired
+			required = append(required, "body")
+		}
+		describeBody(bodySchema, body.MediaType)
+		properties["body"] = bodySchema
 	}
 
 	// Build description
@@ -143,62 +334,243 @@ func convertOperationToMCPTool(
 	if description == "" {
 		description = fmt.Sprintf("%s %s", method, path)
 	}
+	description = describeOperation(description, operation.Extensions)
+
+	inputSchema := mcp.ToolInputSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   required,
+	}
+	if len(converter.defs) > 0 {
+		inputSchema.Defs = converter.defs
+	}
 
-	return &mcp.Tool{
-		Name:        operation.ID,
-		Description: description,
-		InputSchema: mcp.ToolInputSchema{
-			Type:       "object",
-			Properties: properties,
-			Required:   required,
+	return &Tool{
+		Tool: mcp.Tool{
+			Name:        operationName(swaggerOperationName(operation), method, path),
+			Description: description,
+			InputSchema: inputSchema,
+		},
+		Operation: Operation{
+			Method:     method,
+			URL:        path,
+			Parameters: parameters,
+			Body:       body,
+			Responses:  convertSwaggerResponses(root, operation.Responses, operation.Produces),
+			Confirm:    extensionBool(operation.Extensions, ExtensionConfirm),
 		},
 	}, nil
 }
 
-func convertSchemaToMap(schema *spec.Schema) map[string]interface{} {
+// Converts a body parameter into the schema of the body argument, sent as the first media type the operation consumes
+// The spec says JSON is assumed when nothing is declared
+func convertSwaggerBody(converter *swaggerSchemaConverter, schema *spec.Schema, consumes []string) (map[string]interface{}, *RequestBody) {
+	mediaType := selectMediaType(consumes)
+	encoding := bodyEncoding(mediaType)
+	// form encodings are only valid with formData parameters
+	if mediaType == "" || encoding == BodyEncodingForm || encoding == BodyEncodingMultipart {
+		mediaType = "application/json"
+		encoding = BodyEncodingJSON
+	}
+
+	var bodySchema map[string]interface{}
+	if encoding == BodyEncodingJSON {
+		bodySchema = converter.convert(schema)
+	} else {
+		bodySchema = rawBodySchema(encoding, mediaType)
+	}
+	describeBody(bodySchema, mediaType)
+
+	return bodySchema, &RequestBody{MediaType: mediaType, Encoding: encoding}
+}
+
+// Converts Swagger schemas to draft 2020-12 JSON Schema, like schemaConverter does for OpenAPI schemas
+// Expanding the spec leaves the $refs of recursive definitions in place, those are resolved against the root spec
+// and emitted once under $defs
+type swaggerSchemaConverter struct {
+	root      *spec.Swagger
+	direction schemaDirection
+	defs      map[string]interface{}
+	visiting  map[string]string
+	recursive map[string]string
+	taken     map[string]bool
+}
+
+func newSwaggerSchemaConverter(root *spec.Swagger, direction schemaDirection) *swaggerSchemaConverter {
+	return &swaggerSchemaConverter{
+		root:      root,
+		direction: direction,
+		defs:      make(map[string]interface{}),
+		visiting:  make(map[string]string),
+		recursive: make(map[string]string),
+		taken:     make(map[string]bool),
+	}
+}
+
+// Converts a Swagger schema to a standalone JSON Schema, recursive definitions are placed under $defs
+func convertSchemaToMap(root *spec.Swagger, schema *spec.Schema, direction schemaDirection) map[string]interface{} {
+	converter := newSwaggerSchemaConverter(root, direction)
+	result := converter.convert(schema)
+	if len(converter.defs) > 0 {
+		result["$defs"] = converter.defs
+	}
+	return result
+}
+
+func (c *swaggerSchemaConverter) convert(schema *spec.Schema) map[string]interface{} {
+	// an absent schema places no constraints on the value
 	if schema == nil {
-		return map[string]interface{}{"type": "object"}
+		return map[string]interface{}{}
+	}
+
+	ref := schema.Ref.String()
+	if ref == "" {
+		return c.convertSchema(schema)
+	}
+
+	if name, ok := c.recursive[ref]; ok {
+		return defRef(name)
+	}
+
+	// We're already inside this definition so it's recursive, point back at it instead of descending again
+	if name, ok := c.visiting[ref]; ok {
+		c.recursive[ref] = name
+		return defRef(name)
+	}
+
+	// a $ref that can't be resolved places no constraints on the value
+	if c.root == nil {
+		return map[string]interface{}{}
 	}
+	resolved, err := spec.ResolveRef(c.root, &schema.Ref)
+	if err != nil || resolved == nil {
+		return map[string]interface{}{}
+	}
+
+	name := uniqueDefName(c.taken, ref)
+	c.visiting[ref] = name
+	result := c.convert(resolved)
+	delete(c.visiting, ref)
+
+	if _, ok := c.recursive[ref]; ok {
+		c.defs[name] = result
+		return defRef(name)
+	}
+
+	// the name was only reserved in case the definition turned out to be recursive
+	delete(c.taken, name)
+	return result
+}
 
+// x-nullable, Swagger's stand-in for nullable, adds null to the type and the enum, or to an anyOf when there's no type
+func (c *swaggerSchemaConverter) convertSchema(schema *spec.Schema) map[string]interface{} {
 	result := make(map[string]interface{})
+	nullable := extensionFlag(schema.Extensions, "x-nullable")
 
 	// Handle basic properties
+	var schemaType string
 	if schema.Type.Contains("array") {
-		result["type"] = "array"
-		if schema.Items != nil && schema.Items.Schema != nil {
-			result["items"] = convertSchemaToMap(schema.Items.Schema)
-		}
+		schemaType = "array"
 	} else if len(schema.Type) > 0 {
-		result["type"] = schema.Type[0]
+		schemaType = schema.Type[0]
+	}
+	if schemaType != "" && nullable {
+		result["type"] = []string{schemaType, "null"}
+	} else if schemaType != "" {
+		result["type"] = schemaType
+	}
+	if schema.Title != "" {
+		result["title"] = schema.Title
 	}
-
 	if schema.Format != "" {
 		result["format"] = schema.Format
 	}
 	if schema.Description != "" {
 		result["description"] = schema.Description
 	}
+	if len(schema.Enum) > 0 {
+		enum := schema.Enum
+		if nullable && !containsNil(enum) {
+			enum = append(append([]interface{}{}, enum...), nil)
+		}
+		result["enum"] = enum
+	}
+	if schema.Default != nil {
+		result["default"] = schema.Default
+	}
+	if schema.Example != nil {
+		result["examples"] = []interface{}{schema.Example}
+	}
+	if schema.ReadOnly {
+		result["readOnly"] = true
+	}
+
+	// Handle composition, Swagger only has allOf
+	if len(schema.AllOf) > 0 {
+		allOf := make([]interface{}, 0, len(schema.AllOf))
+		for i := range schema.AllOf {
+			allOf = append(allOf, c.convert(&schema.AllOf[i]))
+		}
+		result["allOf"] = allOf
+	}
+
+	// Handle array type
+	if schemaType == "array" && schema.Items != nil && schema.Items.Schema != nil {
+		result["items"] = c.convert(schema.Items.Schema)
+	}
+	if schema.MinItems != nil {
+		result["minItems"] = *schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		result["maxItems"] = *schema.MaxItems
+	}
+	if schema.UniqueItems {
+		result["uniqueItems"] = true
+	}
 
 	// Handle object properties
 	if len(schema.Properties) > 0 {
 		props := make(map[string]interface{})
 		for name, prop := range schema.Properties {
-			props[name] = convertSchemaToMap(&prop)
+			props[name] = c.convert(&prop)
 		}
 		result["properties"] = props
 	}
-
-	// Handle required fields
-	if len(schema.Required) > 0 {
-		result["required"] = schema.Required
+	if required := c.required(schema); len(required) > 0 {
+		result["required"] = required
+	}
+	if schema.AdditionalProperties != nil {
+		if schema.AdditionalProperties.Schema != nil {
+			result["additionalProperties"] = c.convert(schema.AdditionalProperties.Schema)
+		} else {
+			result["additionalProperties"] = schema.AdditionalProperties.Allows
+		}
+	}
+	if schema.MinProperties != nil {
+		result["minProperties"] = *schema.MinProperties
+	}
+	if schema.MaxProperties != nil {
+		result["maxProperties"] = *schema.MaxProperties
 	}
 
 	// Handle constraints
+	// Swagger exclusive bounds are flags on minimum/maximum, JSON Schema uses the bound itself
 	if schema.Minimum != nil {
-		result["minimum"] = *schema.Minimum
+		if schema.ExclusiveMinimum {
+			result["exclusiveMinimum"] = *schema.Minimum
+		} else {
+			result["minimum"] = *schema.Minimum
+		}
 	}
 	if schema.Maximum != nil {
-		result["maximum"] = *schema.Maximum
+		if schema.ExclusiveMaximum {
+			result["exclusiveMaximum"] = *schema.Maximum
+		} else {
+			result["maximum"] = *schema.Maximum
+		}
+	}
+	if schema.MultipleOf != nil {
+		result["multipleOf"] = *schema.MultipleOf
 	}
 	if schema.MinLength != nil {
 		result["minLength"] = *schema.MinLength
@@ -209,24 +581,122 @@ func convertSchemaToMap(schema *spec.Schema) map[string]interface{} {
 	if schema.Pattern != "" {
 		result["pattern"] = schema.Pattern
 	}
-	if len(schema.Enum) > 0 {
-		result["enum"] = schema.Enum
+
+	if nullable && schemaType == "" {
+		return allowNull(result)
+	}
+
+	return result
+}
+
+// Leaves the properties that never travel in the converter's direction out of the required ones
+func (c *swaggerSchemaConverter) required(schema *spec.Schema) []string {
+	if c.direction == directionAny {
+		return schema.Required
+	}
+
+	// Swagger has no writeOnly, only readOnly properties are left out
+	required := make([]string, 0, len(schema.Required))
+	for _, name := range schema.Required {
+		if prop, ok := schema.Properties[name]; ok && c.direction.omits(c.resolve(&prop).ReadOnly, false) {
+			continue
+		}
+		required = append(required, name)
+	}
+	return required
+}
+
+// Follows the $ref of a schema, a schema whose $ref can't be resolved is returned as is
+func (c *swaggerSchemaConverter) resolve(schema *spec.Schema) *spec.Schema {
+	if schema.Ref.String() == "" || c.root == nil {
+		return schema
+	}
+	resolved, err := spec.ResolveRef(c.root, &schema.Ref)
+	if err != nil || resolved == nil {
+		return schema
+	}
+	return resolved
+}
+
+// Converts the inline type information of a non-body Swagger parameter (or its items) to a map
+func convertSimpleSchemaToMap(simple *spec.SimpleSchema, validations *spec.CommonValidations) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	if simple.Type != "" && simple.Type != "file" {
+		result["type"] = simple.Type
+	}
+	if simple.Format != "" {
+		result["format"] = simple.Format
+	}
+	if simple.Type == "array" && simple.Items != nil {
+		result["items"] = convertSimpleSchemaToMap(&simple.Items.SimpleSchema, &simple.Items.CommonValidations)
+	}
+	if simple.Default != nil {
+		result["default"] = simple.Default
+	}
+
+	// Handle constraints
+	if validations.Minimum != nil {
+		result["minimum"] = *validations.Minimum
+	}
+	if validations.Maximum != nil {
+		result["maximum"] = *validations.Maximum
+	}
+	if validations.MinLength != nil {
+		result["minLength"] = *validations.MinLength
+	}
+	if validations.MaxLength != nil {
+		result["maxLength"] = *validations.MaxLength
+	}
+	if validations.Pattern != "" {
+		result["pattern"] = validations.Pattern
+	}
+	if len(validations.Enum) > 0 {
+		result["enum"] = validations.Enum
 	}
 
 	return result
 }
 
 // LoadSwaggerSpec loads a Swagger 2.0 specification from a file or URL
-func LoadSwaggerSpec(specPath string) (*spec.Swagger, error) {
-	var doc *loads.Document
-	var err error
+// With WithLenient an invalid spec is returned anyway and every problem is reported as a diagnostic
+// An overlay passed with WithOverlay is applied once the spec is validated
+func LoadSwaggerSpec(specPath string, opts ...ParseOption) (*spec.Swagger, error) {
+	config := newParseConfig(opts)
+	fetcher, err := config.specFetcher(specPath)
+	if err != nil {
+		return nil, err
+	}
 
-	if isURL(specPath) {
-		doc, err = loads.Spec(specPath)
-	} else {
-		doc, err = loads.Spec(specPath)
+	// The spec and the documents its $refs point at are read by the fetcher, which keeps them next to the spec
+	load := func(location string) (json.RawMessage, error) {
+		parsed, err := url.Parse(location)
+		if err != nil {
+			return nil, err
+		}
+		document, err := fetcher.read(parsed)
+		if err != nil {
+			return nil, err
+		}
+		return document.content, nil
 	}
 
+	doc, err := loads.Spec(fetcher.root.String(), loads.WithDocLoader(load))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Swagger spec: %w", err)
+	}
+
+	// $refs are resolved up front by the fetcher, the validator can't follow the relative $refs of a remote spec
+	// and parameters and schemas from other documents would be missing otherwise
+	expanded, err := doc.Expanded()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve Swagger spec references: %w", err)
+	}
+	raw, err := json.Marshal(expanded.Spec())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve Swagger spec references: %w", err)
+	}
+	doc, err = loads.Analyzed(raw, "", loads.WithDocLoader(load))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load Swagger spec: %w", err)
 	}
@@ -237,12 +707,17 @@ func LoadSwaggerSpec(specPath string) (*spec.Swagger, error) {
 	// Validate the document
 	result, _ := validator.Validate(doc)
 	if result.HasErrors() {
-		return nil, fmt.Errorf("invalid Swagger spec: %v", result.Errors)
+		if !config.lenient {
+			return nil, fmt.Errorf("invalid Swagger spec: %v", result.Errors)
+		}
+		reportSwaggerProblems(doc.Spec(), config, result.Errors)
 	}
 
-	return doc.Spec(), nil
-}
+	if config.overlay != nil {
+		if err := applySwaggerOverlay(doc.Spec(), config.overlay); err != nil {
+			return nil, err
+		}
+	}
 
-func isURL(str string) bool {
-	return len(str) > 8 && (str[:7] == "http://" || str[:8] == "https://")
+	return doc.Spec(), nil
 }
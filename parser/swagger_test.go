@@ -0,0 +1,357 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertSwaggerToMCPTools_PathParameters(t *testing.T) {
+	var doc spec.Swagger
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "pets", "version": "1"},
+  "host": "pets.example.com",
+  "paths": {
+    "/pets/{id}": {
+      "parameters": [
+        {"name": "id", "in": "path", "required": true, "type": "string"},
+        {"name": "fields", "in": "query", "type": "array", "items": {"type": "string"}}
+      ],
+      "get": {
+        "operationId": "getPet",
+        "parameters": [{"name": "fields", "in": "query", "type": "array", "items": {"type": "string"}, "collectionFormat": "pipes"}],
+        "responses": {"200": {"description": "ok"}}
+      },
+      "head": {"operationId": "petExists", "responses": {"200": {"description": "exists"}}}
+    }
+  }
+}`), &doc))
+
+	tools, err := ConvertSwaggerToMCPTools(&doc)
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+
+	assert.Equal(t, "getPet", tools[0].Name)
+	assert.Equal(t, []Parameter{
+		{Name: "id", In: ParameterInPath, Style: StyleSimple, Required: true},
+		{Name: "fields", In: ParameterInQuery, Style: StylePipeDelimited},
+	}, tools[0].Operation.Parameters)
+
+	assert.Equal(t, "petExists", tools[1].Name)
+	assert.Equal(t, "HEAD", tools[1].Operation.Method)
+	assert.Equal(t, []string{"id"}, tools[1].InputSchema.Required)
+}
+
+func TestConvertSwaggerToMCPTools_FormData(t *testing.T) {
+	var doc spec.Swagger
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "pets", "version": "1"},
+  "host": "pets.example.com",
+  "consumes": ["application/xml"],
+  "paths": {
+    "/pets/{id}": {
+      "post": {
+        "operationId": "updatePetWithForm",
+        "consumes": ["application/x-www-form-urlencoded"],
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "string"},
+          {"name": "name", "in": "formData", "required": true, "type": "string"}
+        ],
+        "responses": {"200": {"description": "ok"}}
+      },
+      "put": {
+        "operationId": "replacePet",
+        "parameters": [{"name": "pet", "in": "body", "schema": {"type": "object"}}],
+        "responses": {"200": {"description": "ok"}}
+      }
+    },
+    "/pets/{id}/photo": {
+      "post": {
+        "operationId": "uploadPhoto",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "string"},
+          {"name": "photo", "in": "formData", "type": "file"}
+        ],
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`), &doc))
+
+	tools, err := ConvertSwaggerToMCPTools(&doc)
+	require.NoError(t, err)
+	require.Len(t, tools, 3)
+
+	form := tools[0]
+	assert.Equal(t, "updatePetWithForm", form.Name)
+	assert.Equal(t, &RequestBody{MediaType: "application/x-www-form-urlencoded", Encoding: BodyEncodingForm}, form.Operation.Body)
+	assert.Equal(t, []string{"id", "body"}, form.InputSchema.Required)
+	assert.Equal(t, []string{"name"}, form.InputSchema.Properties["body"].(map[string]interface{})["required"])
+	assert.NotContains(t, form.InputSchema.Properties, "name")
+
+	replace := tools[1]
+	assert.Equal(t, &RequestBody{MediaType: "application/xml", Encoding: BodyEncodingText}, replace.Operation.Body)
+
+	upload := tools[2]
+	assert.Equal(t, &RequestBody{MediaType: "multipart/form-data", Encoding: BodyEncodingMultipart, FileFields: []string{"photo"}}, upload.Operation.Body)
+}
+
+func TestConvertSwaggerToMCPTools_Extensions(t *testing.T) {
+	var doc spec.Swagger
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "pets", "version": "1"},
+  "host": "pets.example.com",
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "getPets",
+        "x-mcp-name": "list_pets",
+        "x-mcp-description": "Lists the pets",
+        "parameters": [
+          {"name": "format", "in": "query", "type": "string", "x-mcp-default": "json"},
+          {"name": "limit", "in": "query", "type": "integer", "x-mcp-examples": 10}
+        ],
+        "responses": {"200": {"description": "ok"}}
+      },
+      "delete": {"operationId": "purgePets", "x-mcp-hidden": true, "responses": {"204": {"description": "purged"}}}
+    }
+  }
+}`), &doc))
+
+	tools, err := ConvertSwaggerToMCPTools(&doc)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	assert.Equal(t, "list_pets", tools[0].Name)
+	assert.Equal(t, "Lists the pets", tools[0].Description)
+	assert.NotContains(t, tools[0].InputSchema.Properties, "format")
+	assert.Equal(t, []interface{}{float64(10)}, tools[0].InputSchema.Properties["limit"].(map[string]interface{})["examples"])
+	assert.Equal(t, "json", tools[0].Operation.Parameters[0].Fixed)
+}
+
+func TestConvertSwaggerToMCPTools_FormDataExtensions(t *testing.T) {
+	specPath := writeSpec(t, t.TempDir(), "swagger.json", `{
+  "swagger": "2.0",
+  "info": {"title": "pets", "version": "1"},
+  "host": "pets.example.com",
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "consumes": ["application/x-www-form-urlencoded"],
+        "parameters": [
+          {"name": "name", "in": "formData", "required": true, "type": "string", "x-mcp-description": "Name of the pet"},
+          {"name": "tag", "in": "formData", "type": "string", "x-mcp-examples": ["dog", "cat"]},
+          {"name": "source", "in": "formData", "required": true, "type": "string", "x-mcp-default": "axon"},
+          {"name": "internal", "in": "formData", "type": "string", "x-mcp-hidden": true},
+          {"name": "status", "in": "formData", "type": "string"}
+        ],
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`)
+	overlay := &Overlay{Operations: map[string]OperationOverlay{
+		"createPet": {Parameters: map[string]ParameterOverlay{
+			"status": {Description: "Where the pet is in the adoption process", Examples: []interface{}{"available"}},
+		}},
+	}}
+
+	tools, err := ParseSpecRouter(specPath, WithOverlay(overlay))
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	body := tools[0].InputSchema.Properties["body"].(map[string]interface{})
+	fields := body["properties"].(map[string]interface{})
+	assert.Equal(t, "Name of the pet", fields["name"].(map[string]interface{})["description"])
+	assert.Equal(t, []interface{}{"dog", "cat"}, fields["tag"].(map[string]interface{})["examples"])
+	assert.Equal(t, map[string]interface{}{
+		"type":        "string",
+		"description": "Where the pet is in the adoption process",
+		"examples":    []interface{}{"available"},
+	}, fields["status"])
+	assert.NotContains(t, fields, "source")
+	assert.NotContains(t, fields, "internal")
+	assert.Equal(t, []string{"name"}, body["required"])
+	assert.Equal(t, map[string]interface{}{"source": "axon"}, tools[0].Operation.Body.FixedFields)
+}
+
+func TestConvertSwaggerSchemaToMap(t *testing.T) {
+	var schema spec.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "type": "object",
+  "required": ["name"],
+  "allOf": [
+    {"type": "object", "properties": {"id": {"type": "integer", "minimum": 0, "exclusiveMinimum": true}}}
+  ],
+  "properties": {
+    "name": {"type": "string", "default": "Rex", "example": "Tom", "maxLength": 20},
+    "nickname": {"type": "string", "x-nullable": true},
+    "size": {"type": "string", "enum": ["small", "large"], "x-nullable": true},
+    "tags": {"type": "object", "additionalProperties": {"type": "string"}},
+    "extra": {"type": "object", "additionalProperties": false}
+  }
+}`), &schema))
+
+	assert.Equal(t, map[string]interface{}{
+		"type":     "object",
+		"required": []string{"name"},
+		"allOf": []interface{}{
+			map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"id": map[string]interface{}{"type": "integer", "exclusiveMinimum": float64(0)}},
+			},
+		},
+		"properties": map[string]interface{}{
+			"name":     map[string]interface{}{"type": "string", "default": "Rex", "examples": []interface{}{"Tom"}, "maxLength": int64(20)},
+			"nickname": map[string]interface{}{"type": []string{"string", "null"}},
+			"size":     map[string]interface{}{"type": []string{"string", "null"}, "enum": []interface{}{"small", "large", nil}},
+			"tags":     map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+			"extra":    map[string]interface{}{"type": "object", "additionalProperties": false},
+		},
+	}, convertSchemaToMap(nil, &schema, directionAny))
+
+	assert.Equal(t, map[string]interface{}{}, convertSchemaToMap(nil, nil, directionAny))
+
+	var untyped spec.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{"description": "The owner", "x-nullable": true, "allOf": [{"type": "object"}]}`), &untyped))
+	assert.Equal(t, map[string]interface{}{
+		"description": "The owner",
+		"anyOf": []interface{}{
+			map[string]interface{}{"allOf": []interface{}{map[string]interface{}{"type": "object"}}},
+			map[string]interface{}{"type": "null"},
+		},
+	}, convertSchemaToMap(nil, &untyped, directionAny))
+}
+
+func TestConvertSwaggerToMCPTools_RecursiveDefinition(t *testing.T) {
+	document := `{
+  "swagger": "2.0",
+  "info": {"title": "tree", "version": "1"},
+  "host": "tree.example.com",
+  "paths": {
+    "/nodes": {
+      "post": {
+        "operationId": "createNode",
+        "parameters": [{"name": "node", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Node"}}],
+        "responses": {"200": {"description": "ok", "schema": {"$ref": "#/definitions/Node"}}}
+      }
+    }
+  },
+  "definitions": {
+    "Node": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "children": {"type": "array", "items": {"$ref": "#/definitions/Node"}}
+      }
+    }
+  }
+}`
+	var doc spec.Swagger
+	require.NoError(t, json.Unmarshal([]byte(document), &doc))
+
+	tools, err := ConvertSwaggerToMCPTools(&doc)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	node := `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"children": {"type": "array", "items": {"$ref": "#/$defs/Node"}}
+		}
+	}`
+
+	body, err := json.Marshal(tools[0].InputSchema.Properties["body"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"$ref": "#/$defs/Node", "description": "Request body, sent as application/json"}`, string(body))
+	defs, err := json.Marshal(tools[0].InputSchema.Defs)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Node": `+node+`}`, string(defs))
+
+	response, err := json.Marshal(tools[0].Operation.Responses["200"].Schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"$ref": "#/$defs/Node", "$defs": {"Node": `+node+`}}`, string(response))
+
+	// expanding a loaded spec leaves the $refs of recursive definitions in place, after unrolling them once
+	tools, err = ParseSpecRouter(writeSpec(t, t.TempDir(), "swagger.json", document))
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "#/$defs/Node", tools[0].InputSchema.Properties["body"].(map[string]interface{})["$ref"])
+	assert.Contains(t, tools[0].InputSchema.Defs, "Node")
+	assert.Equal(t, "#/$defs/Node", tools[0].Operation.Responses["200"].Schema["$ref"])
+}
+
+func TestConvertSwaggerToMCPTools_ReadOnlyRequired(t *testing.T) {
+	var doc spec.Swagger
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "pets", "version": "1"},
+  "host": "pets.example.com",
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "parameters": [{"name": "pet", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Pet"}}],
+        "responses": {"200": {"description": "ok", "schema": {"$ref": "#/definitions/Pet"}}}
+      }
+    }
+  },
+  "definitions": {
+    "Id": {"type": "integer", "readOnly": true},
+    "Pet": {
+      "type": "object",
+      "required": ["id", "name"],
+      "properties": {"id": {"$ref": "#/definitions/Id"}, "name": {"type": "string"}}
+    }
+  }
+}`), &doc))
+
+	tools, err := ConvertSwaggerToMCPTools(&doc)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	assert.Equal(t, []string{"name"}, tools[0].InputSchema.Properties["body"].(map[string]interface{})["required"])
+	assert.Equal(t, []string{"id", "name"}, tools[0].Operation.Responses["200"].Schema["required"])
+}
+
+func TestConvertSwaggerToMCPTools_SharedParameterName(t *testing.T) {
+	var doc spec.Swagger
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "pets", "version": "1"},
+  "host": "pets.example.com",
+  "paths": {
+    "/pets/{id}": {
+      "get": {
+        "operationId": "getPet",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "integer"},
+          {"name": "id", "in": "header", "type": "string"}
+        ],
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`), &doc))
+
+	tools, err := ConvertSwaggerToMCPTools(&doc)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	tool := tools[0]
+	assert.Equal(t, []Parameter{
+		{Name: "id", In: ParameterInPath, Style: StyleSimple, Required: true, Argument: "path.id"},
+		{Name: "id", In: ParameterInHeader, Style: StyleSimple, Argument: "header.id"},
+	}, tool.Operation.Parameters)
+	assert.Contains(t, tool.InputSchema.Properties, "path.id")
+	assert.Contains(t, tool.InputSchema.Properties, "header.id")
+	assert.Equal(t, []string{"path.id"}, tool.InputSchema.Required)
+}
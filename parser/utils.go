@@ -1,90 +1,155 @@
 package parser
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"os"
+	"path/filepath"
 	"strings"
-
-	"github.com/evisdrenova/axon-server/mcp"
 )
 
-type specVersion struct {
-	Swagger string `json:"swagger"`
-	OpenAPI string `json:"openapi"`
+// DetectSpecVersion reads the swagger or openapi version of the JSON or YAML spec at a file path or URL
+func DetectSpecVersion(specPath string, opts ...ParseOption) (string, error) {
+	_, version, err := detectSpecFormat(specPath, opts...)
+	return version, err
 }
 
-func DetectSpecVersion(specPath string) (string, error) {
-	file, err := os.Open(specPath)
+// Reads whether the spec is a Swagger or an OpenAPI document and the version it declares
+func detectSpecFormat(specPath string, opts ...ParseOption) (string, string, error) {
+	fetcher, err := newParseConfig(opts).specFetcher(specPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open spec file: %w", err)
+		return "", "", err
 	}
-	defer file.Close()
 
-	content, err := io.ReadAll(file)
+	document, err := fetcher.readRoot()
 	if err != nil {
-		return "", fmt.Errorf("failed to read spec file: %w", err)
+		return "", "", err
 	}
 
-	var version specVersion
-	if err := json.Unmarshal(content, &version); err != nil {
-		// Try YAML format if JSON fails
-		// Convert common YAML version indicators to JSON
-		yamlContent := string(content)
-		if strings.Contains(yamlContent, "swagger: \"2.0\"") {
-			return "2.0", nil
-		}
-		if strings.Contains(yamlContent, "openapi: \"3.") {
-			return "3.0", nil
+	return document.format()
+}
+
+// ParseSpecRouter converts the spec at a file path or URL into tools
+// A directory is expanded into every spec file it contains, see ExpandSpecPaths
+func ParseSpecRouter(specPath string, opts ...ParseOption) ([]Tool, error) {
+	if !IsURL(specPath) {
+		if info, err := os.Stat(specPath); err == nil && info.IsDir() {
+			return parseSpecDirectory(specPath, opts...)
 		}
-		return "", fmt.Errorf("failed to parse spec version: %w", err)
+	}
+	return parseSpec(specPath, opts...)
+}
+
+// Converts every spec in a directory, tool names must be unique across all of them
+func parseSpecDirectory(dir string, opts ...ParseOption) ([]Tool, error) {
+	if newParseConfig(opts).overlay != nil {
+		return nil, fmt.Errorf("an overlay targets a single spec, it can't be applied to the directory %s", dir)
 	}
 
-	if version.Swagger != "" {
-		return version.Swagger, nil
+	specPaths, err := ExpandSpecPaths(dir)
+	if err != nil {
+		return nil, err
 	}
-	if version.OpenAPI != "" {
-		return version.OpenAPI, nil
+	if len(specPaths) == 0 {
+		return nil, fmt.Errorf("no spec files found in %s", dir)
+	}
+
+	var tools []Tool
+	sources := make(map[string]string)
+	for _, specPath := range specPaths {
+		specTools, err := parseSpec(specPath, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", specPath, err)
+		}
+		for _, tool := range specTools {
+			if source, ok := sources[tool.Name]; ok {
+				return nil, fmt.Errorf("tool %s from %s collides with a tool from %s", tool.Name, specPath, source)
+			}
+			sources[tool.Name] = specPath
+		}
+		tools = append(tools, specTools...)
+	}
+
+	return tools, nil
+}
+
+// ExpandSpecPaths replaces every directory in paths with the .json, .yaml and .yml files directly inside it
+// URLs and files are returned as they are
+func ExpandSpecPaths(paths ...string) ([]string, error) {
+	var expanded []string
+	for _, path := range paths {
+		if IsURL(path) {
+			expanded = append(expanded, path)
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open spec: %w", err)
+		}
+		if !info.IsDir() {
+			expanded = append(expanded, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spec directory: %w", err)
+		}
+		// ReadDir sorts by name so the tool order is stable
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".json", ".yaml", ".yml":
+				expanded = append(expanded, filepath.Join(path, entry.Name()))
+			}
+		}
 	}
 
-	return "", fmt.Errorf("no version information found in spec")
+	return expanded, nil
 }
 
-func ParseSpecRouter(specPath string) ([]mcp.Tool, error) {
-	var tools []mcp.Tool
+func parseSpec(specPath string, opts ...ParseOption) ([]Tool, error) {
+	var tools []Tool
+	opts = append([]ParseOption{WithSpecLocation(specPath)}, opts...)
+
+	// The fetcher is shared by the steps below so the spec is only read once
+	fetcher, err := newParseConfig(opts).specFetcher(specPath)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, withSpecFetcher(fetcher))
 
-	// Detect spec version
-	version, err := DetectSpecVersion(specPath)
+	// Detect spec version, the field it is written in decides how the spec is loaded
+	format, version, err := detectSpecFormat(specPath, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("error detecting spec version: %v", err)
 	}
 
-	// TODO: we could probably make this better by checking the first field of the
-	// spec either swagger or openapi instead of using the version
-	if strings.HasPrefix(version, "2.") {
+	if format == specFormatSwagger && strings.HasPrefix(version, "2.") {
 		// Swagger 2.0
-		swaggerSpec, err := LoadSwaggerSpec(specPath)
+		swaggerSpec, err := LoadSwaggerSpec(specPath, opts...)
 		if err != nil {
-			return nil, fmt.Errorf("unable to load swagger spec")
+			return nil, fmt.Errorf("unable to load swagger spec: %w", err)
 		}
 
-		tools, err = ConvertSwaggerToMCPTools(swaggerSpec)
+		tools, err = ConvertSwaggerToMCPTools(swaggerSpec, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert Swagger spec to MCP tools: %v", err)
 		}
-	} else if strings.HasPrefix(version, "3.") {
-		openApiSpec, err := LoadOpenApiSpec(specPath)
+	} else if format == specFormatOpenAPI && strings.HasPrefix(version, "3.") {
+		openApiSpec, err := LoadOpenApiSpec(specPath, opts...)
 		if err != nil {
-			return nil, fmt.Errorf("error loading OpenAPI spec: %v", err)
+			return nil, fmt.Errorf("error loading OpenAPI spec: %w", err)
 		}
 
-		tools, err = ConvertOpenAPIToMCPTools(openApiSpec)
+		tools, err = ConvertOpenAPIToMCPTools(openApiSpec, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert OpenAPI spec to MCP tools: %v", err)
 		}
 	} else {
-		return nil, fmt.Errorf("unsupported specification version: %s", version)
+		return nil, fmt.Errorf("unsupported specification version: %s %s", format, version)
 	}
 
 	return tools, nil
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1"},
+  "servers": [{"url": "https://pets.example.com"}],
+  "paths": {
+    "/pets": {"get": {"operationId": "listPets", "responses": {"200": {"description": "ok"}}}}
+  }
+}`
+
+func writeSpec(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestExpandSpecPaths(t *testing.T) {
+	dir := t.TempDir()
+	b := writeSpec(t, dir, "b.yaml", "")
+	a := writeSpec(t, dir, "a.json", "")
+	writeSpec(t, dir, "notes.txt", "")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested"), 0755))
+	single := writeSpec(t, t.TempDir(), "single.json", "")
+
+	paths, err := ExpandSpecPaths(dir, single, "https://example.com/spec.json")
+	require.NoError(t, err)
+	assert.Equal(t, []string{a, b, single, "https://example.com/spec.json"}, paths)
+
+	_, err = ExpandSpecPaths(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestParseSpecRouter_Prefix(t *testing.T) {
+	path := writeSpec(t, t.TempDir(), "pets.json", petSpec)
+
+	tools, err := ParseSpecRouter(path, WithToolPrefix("petstore"))
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "petstore_listPets", tools[0].Name)
+}
+
+func TestParseSpecRouter_Directory(t *testing.T) {
+	dir := t.TempDir()
+	writeSpec(t, dir, "pets.json", petSpec)
+	writeSpec(t, dir, "more-pets.json", petSpec)
+
+	_, err := ParseSpecRouter(dir)
+	assert.ErrorContains(t, err, "tool listPets from "+filepath.Join(dir, "pets.json")+" collides with a tool from "+filepath.Join(dir, "more-pets.json"))
+
+	_, err = ParseSpecRouter(t.TempDir())
+	assert.ErrorContains(t, err, "no spec files found")
+}
@@ -0,0 +1,119 @@
+package registry
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/evisdrenova/axon-server/config"
+	"github.com/evisdrenova/axon-server/parser"
+	"github.com/evisdrenova/axon-server/server"
+)
+
+// Tools is what specs register their tools with, an *server.MCPServer
+type Tools interface {
+	HasTool(name string) bool
+	UpdateTools(set []server.ServerTool, remove ...string)
+}
+
+// Report collects the diagnostics of every spec, a *handlers.DiagnosticsReport
+type Report interface {
+	Set(spec string, diagnostics parser.Diagnostics)
+}
+
+// Loaded is what loading a spec produced
+type Loaded struct {
+	Tools       []parser.Tool
+	Diagnostics parser.Diagnostics
+	// Sources are the files and URLs the spec was read from
+	Sources []string
+}
+
+// LoadFunc loads a spec, logging what it finds to logger
+type LoadFunc func(logger *log.Logger) (*Loaded, error)
+
+// BuildFunc builds the handler of a tool
+type BuildFunc func(tool parser.Tool) server.ServerTool
+
+// Spec is a spec whose tools are registered
+type Spec struct {
+	spec   config.SpecConfig
+	tools  Tools
+	report Report
+	load   LoadFunc
+	build  BuildFunc
+	logger *log.Logger
+
+	registered  map[string]parser.Tool
+	diagnostics parser.Diagnostics
+	sources     []string
+
+	// fingerprint and fetched tell when the spec has to be loaded again, see Changed
+	fingerprint string
+	fetched     time.Time
+}
+
+// Changes counts what an update of the tools of a spec did
+type Changes struct {
+	Added, Updated, Removed int
+}
+
+// Register loads a spec and registers its tools, and its diagnostics with the report
+// load is called again whenever the spec is reloaded, build for every tool that is added or changed
+func Register(tools Tools, report Report, spec config.SpecConfig, load LoadFunc, build BuildFunc) (*Spec, error) {
+	r := &Spec{spec: spec, tools: tools, report: report, load: load, build: build, logger: log.Default()}
+
+	loaded, err := load(r.logger)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Update(loaded.Tools); err != nil {
+		return nil, err
+	}
+	r.diagnostics = loaded.Diagnostics
+	r.sources = loaded.Sources
+	r.fingerprint = r.fingerprintFiles()
+	r.fetched = time.Now()
+	report.Set(spec.Path, loaded.Diagnostics)
+	return r, nil
+}
+
+// Update replaces the tools the spec registered with tools, in a single update
+// Tools that didn't change keep their handler, and nothing is updated when no tool changed
+func (r *Spec) Update(tools []parser.Tool) (Changes, error) {
+	var changes Changes
+	var set []server.ServerTool
+	current := make(map[string]parser.Tool, len(tools))
+	for _, tool := range tools {
+		current[tool.Name] = tool
+		previous, ok := r.registered[tool.Name]
+		switch {
+		case !ok:
+			// UpdateTools silently replaces tools with the same name, so collisions between specs are caught here
+			if r.tools.HasTool(tool.Name) {
+				return Changes{}, fmt.Errorf("tool %s is already registered by another spec, set a prefix to tell them apart", tool.Name)
+			}
+			changes.Added++
+		case !reflect.DeepEqual(previous, tool):
+			changes.Updated++
+		default:
+			continue
+		}
+		set = append(set, r.build(tool))
+	}
+
+	var removed []string
+	for name := range r.registered {
+		if _, ok := current[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	changes.Removed = len(removed)
+
+	if len(set) > 0 || len(removed) > 0 {
+		r.tools.UpdateTools(set, removed...)
+	}
+	r.registered = current
+	return changes, nil
+}
@@ -0,0 +1,238 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evisdrenova/axon-server/config"
+	"github.com/evisdrenova/axon-server/handlers"
+	"github.com/evisdrenova/axon-server/mcp"
+	"github.com/evisdrenova/axon-server/parser"
+	"github.com/evisdrenova/axon-server/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petsSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: "1"}
+servers:
+  - url: https://pets.example.com
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200": {description: The pets}
+  /pets/{petId}:
+    parameters:
+      - {name: petId, in: path, required: true, schema: {type: string}}
+    get:
+      operationId: getPet
+      description: Returns a pet
+      responses:
+        "200": {description: A pet}
+    delete:
+      operationId: deletePet
+      responses:
+        "204": {description: Deleted}
+`
+
+// the description of getPet changed, deletePet is gone and createPet is new
+const changedPetsSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: "1"}
+servers:
+  - url: https://pets.example.com
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200": {description: The pets}
+    post:
+      operationId: createPet
+      responses:
+        "201": {description: Created}
+  /pets/{petId}:
+    parameters:
+      - {name: petId, in: path, required: true, schema: {type: string}}
+    get:
+      operationId: getPet
+      description: Returns a single pet by its id
+      responses:
+        "200": {description: A pet}
+`
+
+// countingServer counts the tool updates, each one sends a single list_changed notification to initialized clients
+type countingServer struct {
+	*server.MCPServer
+	updates int
+}
+
+func (s *countingServer) UpdateTools(set []server.ServerTool, remove ...string) {
+	s.updates++
+	s.MCPServer.UpdateTools(set, remove...)
+}
+
+func newCountingServer() *countingServer {
+	return &countingServer{MCPServer: server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(false, false))}
+}
+
+func writeSpec(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+// Registers a spec file, every tool answers with its description so tests can tell handlers apart
+func registerFile(t *testing.T, s *countingServer, specPath string) *Spec {
+	t.Helper()
+	load := func(logger *log.Logger) (*Loaded, error) {
+		var diagnostics parser.Diagnostics
+		var sources []string
+		tools, err := parser.ParseSpecRouter(specPath, parser.WithDiagnostics(&diagnostics), parser.WithSources(&sources))
+		if err != nil {
+			return nil, err
+		}
+		return &Loaded{Tools: tools, Diagnostics: diagnostics, Sources: sources}, nil
+	}
+	build := func(tool parser.Tool) server.ServerTool {
+		return server.ServerTool{Tool: tool.Tool, Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(tool.Description), nil
+		}}
+	}
+
+	spec, err := Register(s, handlers.NewDiagnosticsReport(s.MCPServer), config.SpecConfig{Path: specPath}, load, build)
+	require.NoError(t, err)
+	spec.logger = log.New(io.Discard, "", 0)
+	return spec
+}
+
+func toolNames(s *server.MCPServer) []string {
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}`))
+	var names []string
+	for _, tool := range response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult).Tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func callTool(t *testing.T, s *server.MCPServer, name string) string {
+	t.Helper()
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "`+name+`"}}`))
+	result := response.(mcp.JSONRPCResponse).Result.(*mcp.CallToolResult)
+	return result.Content[0].(mcp.TextContent).Text
+}
+
+func TestSpec_Reload(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "pets.yaml")
+	writeSpec(t, specPath, petsSpec)
+	s := newCountingServer()
+	spec := registerFile(t, s, specPath)
+	watch := config.WatchConfig{Interval: time.Second, RemoteInterval: time.Minute}
+
+	assert.ElementsMatch(t, []string{"listPets", "getPet", "deletePet"}, toolNames(s.MCPServer))
+	assert.Equal(t, 1, s.updates)
+
+	// nothing changed, nothing is updated
+	assert.False(t, spec.Changed(time.Now(), watch))
+	changes, err := spec.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, Changes{}, changes)
+	assert.Equal(t, 1, s.updates)
+
+	writeSpec(t, specPath, changedPetsSpec)
+	assert.True(t, spec.Changed(time.Now(), watch))
+	changes, err = spec.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, Changes{Added: 1, Updated: 1, Removed: 1}, changes)
+	assert.Equal(t, 2, s.updates, "the whole change is a single update")
+	assert.ElementsMatch(t, []string{"listPets", "getPet", "createPet"}, toolNames(s.MCPServer))
+	assert.Equal(t, "Returns a single pet by its id", callTool(t, s.MCPServer, "getPet"))
+	assert.False(t, spec.Changed(time.Now(), watch))
+
+	// a spec that fails to load keeps the tools it had
+	writeSpec(t, specPath, "openapi: 3.0.3\npaths: [")
+	assert.True(t, spec.Changed(time.Now(), watch))
+	_, err = spec.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, 2, s.updates)
+	assert.ElementsMatch(t, []string{"listPets", "getPet", "createPet"}, toolNames(s.MCPServer))
+	assert.Equal(t, "Returns a single pet by its id", callTool(t, s.MCPServer, "getPet"))
+}
+
+// Reloads run on the watcher's goroutine while the transport initializes clients, run with -race
+func TestSpec_ReloadWhileServing(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "pets.yaml")
+	writeSpec(t, specPath, petsSpec)
+	s := newCountingServer()
+	spec := registerFile(t, s, specPath)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 4; i++ {
+			content := changedPetsSpec
+			if i%2 == 1 {
+				content = petsSpec
+			}
+			if assert.NoError(t, os.WriteFile(specPath, []byte(content), 0o644)) {
+				_, err := spec.Reload()
+				assert.NoError(t, err)
+			}
+		}
+	}()
+
+	for i := 0; i < 4; i++ {
+		ctx := s.WithContext(context.Background(), server.NotificationContext{ClientID: "client", SessionID: fmt.Sprint(i)})
+		s.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`))
+	}
+	<-done
+
+	assert.ElementsMatch(t, []string{"listPets", "getPet", "deletePet"}, toolNames(s.MCPServer))
+}
+
+func TestSpec_ReloadCollision(t *testing.T) {
+	dir := t.TempDir()
+	petsPath := filepath.Join(dir, "pets.yaml")
+	writeSpec(t, petsPath, petsSpec)
+	otherPath := filepath.Join(dir, "other.yaml")
+	writeSpec(t, otherPath, `
+openapi: 3.0.3
+info: {title: Other, version: "1"}
+servers:
+  - url: https://other.example.com
+paths:
+  /things:
+    get:
+      operationId: listThings
+      responses:
+        "200": {description: The things}
+`)
+	s := newCountingServer()
+	registerFile(t, s, petsPath)
+	other := registerFile(t, s, otherPath)
+
+	// a reload that would take over a tool of another spec fails and changes nothing
+	writeSpec(t, otherPath, changedPetsSpec)
+	_, err := other.Reload()
+	assert.ErrorContains(t, err, "is already registered by another spec")
+	assert.Equal(t, 2, s.updates)
+	assert.ElementsMatch(t, []string{"listPets", "getPet", "deletePet", "listThings"}, toolNames(s.MCPServer))
+}
+
+func TestSpec_ChangedRemote(t *testing.T) {
+	spec := &Spec{spec: config.SpecConfig{Path: "https://pets.example.com/openapi.json"}, fetched: time.Now()}
+	watch := config.WatchConfig{Interval: time.Second, RemoteInterval: time.Minute}
+	spec.fingerprint = spec.fingerprintFiles()
+
+	assert.False(t, spec.Changed(spec.fetched.Add(30*time.Second), watch))
+	assert.True(t, spec.Changed(spec.fetched.Add(time.Minute), watch))
+	assert.False(t, spec.Changed(spec.fetched.Add(time.Second), watch), "the interval starts over")
+}
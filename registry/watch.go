@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/evisdrenova/axon-server/config"
+	"github.com/evisdrenova/axon-server/parser"
+)
+
+// Watch polls the specs and reloads the ones that changed, for as long as the process runs
+// Their tools are added, updated and removed in place and clients are told the tool list changed
+func Watch(specs []*Spec, watch config.WatchConfig) {
+	ticker := time.NewTicker(watch.Interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		for _, spec := range specs {
+			if spec.Changed(now, watch) {
+				spec.Reload()
+			}
+		}
+	}
+}
+
+// Changed reports whether the spec may have changed since it was last loaded
+// Files are compared by size and modification time, specs from a URL are fetched again every remote interval
+// and only reloaded when what they produce changed
+func (r *Spec) Changed(now time.Time, watch config.WatchConfig) bool {
+	fingerprint := r.fingerprintFiles()
+	changed := fingerprint != r.fingerprint
+	r.fingerprint = fingerprint
+
+	if parser.IsURL(r.spec.Path) && now.Sub(r.fetched) >= watch.RemoteInterval {
+		r.fetched = now
+		changed = true
+	}
+	return changed
+}
+
+// Describes the local files of the spec: the spec, the files it references and its overlay
+func (r *Spec) fingerprintFiles() string {
+	files := append([]string(nil), r.sources...)
+	if r.spec.Overlay != "" {
+		files = append(files, r.spec.Overlay)
+	}
+	// a directory of specs also changes when a spec is added to it
+	if expanded, err := parser.ExpandSpecPaths(r.spec.Path); err == nil {
+		files = append(files, expanded...)
+	}
+	sort.Strings(files)
+	files = slices.Compact(files)
+
+	var fingerprint strings.Builder
+	for _, file := range files {
+		if parser.IsURL(file) {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			fmt.Fprintf(&fingerprint, "%s missing\n", file)
+			continue
+		}
+		fmt.Fprintf(&fingerprint, "%s %d %d\n", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return fingerprint.String()
+}
+
+// Reload loads the spec again and replaces its tools with the ones it has now
+// A spec that fails to load keeps the tools it had, so a half saved file doesn't take tools away
+func (r *Spec) Reload() (Changes, error) {
+	// what loading logs is only worth repeating when something changed, remote specs are loaded every interval
+	var output bytes.Buffer
+	loaded, err := r.load(log.New(&output, r.logger.Prefix(), r.logger.Flags()))
+	if err == nil {
+		var changes Changes
+		changes, err = r.Update(loaded.Tools)
+		if err == nil {
+			r.reloaded(loaded, changes, output.Bytes())
+			return changes, nil
+		}
+	}
+
+	r.logger.Writer().Write(output.Bytes())
+	r.logger.Printf("%s: failed to reload, keeping the tools it had: %v", r.spec.Path, err)
+	return Changes{}, err
+}
+
+// Records a spec that loaded again, and logs what changed if anything did
+func (r *Spec) reloaded(loaded *Loaded, changes Changes, output []byte) {
+	r.sources = loaded.Sources
+	r.fingerprint = r.fingerprintFiles()
+	if changes == (Changes{}) && reflect.DeepEqual(loaded.Diagnostics, r.diagnostics) {
+		return
+	}
+	r.diagnostics = loaded.Diagnostics
+	r.report.Set(r.spec.Path, loaded.Diagnostics)
+
+	r.logger.Writer().Write(output)
+	r.logger.Printf("%s: reloaded, %d tools added, %d updated and %d removed", r.spec.Path, changes.Added, changes.Updated, changes.Removed)
+}
@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
+	"sync"
 
+	"github.com/evisdrenova/axon-server/jsonschema"
 	"github.com/evisdrenova/axon-server/mcp"
 )
 
@@ -68,6 +71,10 @@ type MCPServer struct {
 	notifications        chan ServerNotification
 	currentClient        NotificationContext
 	initialized          bool
+	validateArguments    bool
+	// mu guards resources, tools, currentClient and initialized, which tool handlers and spec reloads
+	// use while requests are served
+	mu sync.RWMutex
 }
 
 // serverKey is the context key for storing the server instance
@@ -86,7 +93,9 @@ func (s *MCPServer) WithContext(
 	ctx context.Context,
 	notifCtx NotificationContext,
 ) context.Context {
+	s.mu.Lock()
 	s.currentClient = notifCtx
+	s.mu.Unlock()
 	return ctx
 }
 
@@ -109,9 +118,13 @@ func (s *MCPServer) SendNotificationToClient(
 		},
 	}
 
+	s.mu.RLock()
+	client := s.currentClient
+	s.mu.RUnlock()
+
 	select {
 	case s.notifications <- ServerNotification{
-		Context:      s.currentClient,
+		Context:      client,
 		Notification: notification,
 	}:
 		return nil
@@ -164,6 +177,14 @@ func WithLogging() ServerOption {
 	}
 }
 
+// WithArgumentValidation checks tool call arguments against the tool's input schema before calling its handler
+// Calls with invalid arguments get an error result that lists every problem so the model can correct them
+func WithArgumentValidation() ServerOption {
+	return func(s *MCPServer) {
+		s.validateArguments = true
+	}
+}
+
 // NewMCPServer creates a new MCP server instance with the given name, version and options
 func NewMCPServer(
 	name, version string,
@@ -340,7 +361,7 @@ func (s *MCPServer) HandleMessage(
 		}
 		return s.handleGetPrompt(ctx, baseMessage.ID, request)
 	case "tools/list":
-		if len(s.tools) == 0 {
+		if !s.hasTools() {
 			return createErrorResponse(
 				baseMessage.ID,
 				mcp.METHOD_NOT_FOUND,
@@ -357,7 +378,7 @@ func (s *MCPServer) HandleMessage(
 		}
 		return s.handleListTools(ctx, baseMessage.ID, request)
 	case "tools/call":
-		if len(s.tools) == 0 {
+		if !s.hasTools() {
 			return createErrorResponse(
 				baseMessage.ID,
 				mcp.METHOD_NOT_FOUND,
@@ -390,10 +411,35 @@ func (s *MCPServer) AddResource(
 	if s.capabilities.resources == nil {
 		panic("Resource capabilities not enabled")
 	}
+	s.mu.Lock()
 	s.resources[resource.URI] = resourceEntry{
 		resource: resource,
 		handler:  handler,
 	}
+	s.mu.Unlock()
+
+	s.notifyResourcesChanged()
+}
+
+// RemoveResource unregisters the resource with the given URI
+func (s *MCPServer) RemoveResource(uri string) {
+	s.mu.Lock()
+	_, ok := s.resources[uri]
+	delete(s.resources, uri)
+	s.mu.Unlock()
+
+	if ok {
+		s.notifyResourcesChanged()
+	}
+}
+
+// Tells the client the resource list changed if it asked to be told
+func (s *MCPServer) notifyResourcesChanged() {
+	if s.isInitialized() && s.capabilities.resources.listChanged {
+		if err := s.SendNotificationToClient("notifications/resources/list_changed", nil); err != nil {
+			// We can't return the error, but in a future version we could log it
+		}
+	}
 }
 
 // AddResourceTemplate registers a new resource template and its handler
@@ -419,19 +465,71 @@ func (s *MCPServer) AddPrompt(prompt mcp.Prompt, handler PromptHandlerFunc) {
 	s.promptHandlers[prompt.Name] = handler
 }
 
+// ServerTool is a tool together with its handler
+type ServerTool struct {
+	Tool    mcp.Tool
+	Handler ToolHandlerFunc
+}
+
 // AddTool registers a new tool and its handler
 func (s *MCPServer) AddTool(tool mcp.Tool, handler ToolHandlerFunc) {
-	s.tools[tool.Name] = tool
-	s.toolHandlers[tool.Name] = handler
+	s.UpdateTools([]ServerTool{{Tool: tool, Handler: handler}})
+}
+
+// RemoveTool unregisters the tool with the given name
+func (s *MCPServer) RemoveTool(name string) {
+	s.UpdateTools(nil, name)
+}
+
+// UpdateTools adds or replaces the tools in set and removes the tools named in remove in one step
+// Clients never see a tool list with only part of the change, and are notified once
+func (s *MCPServer) UpdateTools(set []ServerTool, remove ...string) {
+	s.mu.Lock()
+	changed := len(set) > 0
+	for _, name := range remove {
+		if _, ok := s.tools[name]; ok {
+			delete(s.tools, name)
+			delete(s.toolHandlers, name)
+			changed = true
+		}
+	}
+	for _, tool := range set {
+		s.tools[tool.Tool.Name] = tool.Tool
+		s.toolHandlers[tool.Tool.Name] = tool.Handler
+	}
+	initialized := s.initialized
+	s.mu.Unlock()
 
 	// Send notification if server is already initialized
-	if s.initialized {
+	if changed && initialized {
 		if err := s.SendNotificationToClient("notifications/tools/list_changed", nil); err != nil {
 			// We can't return the error, but in a future version we could log it
 		}
 	}
 }
 
+// HasTool reports whether a tool with the given name is registered
+// AddTool replaces existing tools, so callers that combine tools from several sources can check for collisions first
+func (s *MCPServer) HasTool(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.tools[name]
+	return ok
+}
+
+// Reports whether a client has initialized the server
+func (s *MCPServer) isInitialized() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.initialized
+}
+
+func (s *MCPServer) hasTools() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.tools) > 0
+}
+
 // AddNotificationHandler registers a new handler for incoming notifications
 func (s *MCPServer) AddNotificationHandler(
 	method string,
@@ -480,7 +578,9 @@ func (s *MCPServer) handleInitialize(
 		Capabilities: capabilities,
 	}
 
+	s.mu.Lock()
 	s.initialized = true
+	s.mu.Unlock()
 	return createResponse(id, result)
 }
 
@@ -497,10 +597,12 @@ func (s *MCPServer) handleListResources(
 	id interface{},
 	request mcp.ListResourcesRequest,
 ) mcp.JSONRPCMessage {
+	s.mu.RLock()
 	resources := make([]mcp.Resource, 0, len(s.resources))
 	for _, entry := range s.resources {
 		resources = append(resources, entry.resource)
 	}
+	s.mu.RUnlock()
 
 	result := mcp.ListResourcesResult{
 		Resources: resources,
@@ -536,7 +638,10 @@ func (s *MCPServer) handleReadResource(
 	request mcp.ReadResourceRequest,
 ) mcp.JSONRPCMessage {
 	// First try direct resource handlers
-	if entry, ok := s.resources[request.Params.URI]; ok {
+	s.mu.RLock()
+	entry, ok := s.resources[request.Params.URI]
+	s.mu.RUnlock()
+	if ok {
 		contents, err := entry.handler(ctx, request)
 		if err != nil {
 			return createErrorResponse(id, mcp.INTERNAL_ERROR, err.Error())
@@ -628,10 +733,12 @@ func (s *MCPServer) handleListTools(
 	id interface{},
 	request mcp.ListToolsRequest,
 ) mcp.JSONRPCMessage {
+	s.mu.RLock()
 	tools := make([]mcp.Tool, 0, len(s.tools))
 	for name := range s.tools {
 		tools = append(tools, s.tools[name])
 	}
+	s.mu.RUnlock()
 
 	result := mcp.ListToolsResult{
 		Tools: tools,
@@ -647,7 +754,11 @@ func (s *MCPServer) handleToolCall(
 	id interface{},
 	request mcp.CallToolRequest,
 ) mcp.JSONRPCMessage {
+	// the handler runs without the lock, it may add resources
+	s.mu.RLock()
+	tool := s.tools[request.Params.Name]
 	handler, ok := s.toolHandlers[request.Params.Name]
+	s.mu.RUnlock()
 	if !ok {
 		return createErrorResponse(
 			id,
@@ -656,6 +767,12 @@ func (s *MCPServer) handleToolCall(
 		)
 	}
 
+	if s.validateArguments {
+		if result := validateToolArguments(tool, request.Params.Arguments); result != nil {
+			return createResponse(id, result)
+		}
+	}
+
 	result, err := handler(ctx, request)
 	if err != nil {
 		return createErrorResponse(id, mcp.INTERNAL_ERROR, err.Error())
@@ -664,6 +781,38 @@ func (s *MCPServer) handleToolCall(
 	return createResponse(id, result)
 }
 
+// Checks the arguments of a tool call and returns an error result describing the violations, if there are any
+func validateToolArguments(tool mcp.Tool, arguments map[string]interface{}) *mcp.CallToolResult {
+	schema := map[string]interface{}{
+		"type":       tool.InputSchema.Type,
+		"properties": tool.InputSchema.Properties,
+	}
+	if len(tool.InputSchema.Required) > 0 {
+		schema["required"] = tool.InputSchema.Required
+	}
+	if len(tool.InputSchema.Defs) > 0 {
+		schema["$defs"] = tool.InputSchema.Defs
+	}
+
+	var args interface{} = arguments
+	if arguments == nil {
+		args = map[string]interface{}{}
+	}
+
+	violations := jsonschema.Validate(schema, args)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "Invalid arguments for %s:\n", tool.Name)
+	for _, violation := range violations {
+		fmt.Fprintf(&message, "- %s\n", violation)
+	}
+	message.WriteString("Fix the arguments and call the tool again.")
+	return mcp.NewToolResultError(message.String())
+}
+
 func (s *MCPServer) handleNotification(
 	ctx context.Context,
 	notification mcp.JSONRPCNotification,
@@ -16,6 +16,110 @@ func TestMCPServer_NewMCPServer(t *testing.T) {
 	assert.Equal(t, "1.0.0", server.version)
 }
 
+func TestMCPServer_HasTool(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	assert.False(t, server.HasTool("test-tool"))
+
+	server.AddTool(mcp.Tool{Name: "test-tool"}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+	assert.True(t, server.HasTool("test-tool"))
+}
+
+func TestMCPServer_UpdateTools(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	handler := func(text string) ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(text), nil
+		}
+	}
+	server.AddTool(mcp.Tool{Name: "list_pets"}, handler("old"))
+	server.AddTool(mcp.Tool{Name: "delete_pet"}, handler("deleted"))
+	server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`))
+
+	server.UpdateTools([]ServerTool{
+		{Tool: mcp.Tool{Name: "list_pets", Description: "Lists the pets"}, Handler: handler("new")},
+		{Tool: mcp.Tool{Name: "get_pet"}, Handler: handler("pet")},
+	}, "delete_pet")
+
+	response := server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`))
+	result := response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult)
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.ElementsMatch(t, []string{"list_pets", "get_pet"}, names)
+
+	response = server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "list_pets"}}`))
+	call := response.(mcp.JSONRPCResponse).Result.(*mcp.CallToolResult)
+	assert.Equal(t, "new", call.Content[0].(mcp.TextContent).Text)
+
+	// the whole change is announced once
+	assert.Len(t, server.notifications, 1)
+	notification := <-server.notifications
+	assert.Equal(t, "notifications/tools/list_changed", notification.Notification.Method)
+
+	server.RemoveTool("get_pet")
+	assert.False(t, server.HasTool("get_pet"))
+	assert.Len(t, server.notifications, 1)
+
+	// removing a tool that isn't there changes nothing
+	server.RemoveTool("get_pet")
+	assert.Len(t, server.notifications, 1)
+}
+
+func TestMCPServer_RemoveResource(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(false, false))
+	server.AddResource(mcp.Resource{URI: "test://resource"}, func(ctx context.Context, request mcp.ReadResourceRequest) ([]interface{}, error) {
+		return nil, nil
+	})
+
+	read := func() mcp.JSONRPCMessage {
+		return server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": {"uri": "test://resource"}}`))
+	}
+
+	_, ok := read().(mcp.JSONRPCResponse)
+	assert.True(t, ok)
+
+	server.RemoveResource("test://resource")
+	_, ok = read().(mcp.JSONRPCError)
+	assert.True(t, ok)
+}
+
+func TestMCPServer_ArgumentValidation(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithArgumentValidation())
+	called := false
+	server.AddTool(mcp.Tool{
+		Name: "getPet",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"petId": map[string]interface{}{"type": "integer"},
+			},
+			Required: []string{"petId"},
+		},
+	}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = true
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	call := func(arguments string) *mcp.CallToolResult {
+		response := server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "getPet", "arguments": `+arguments+`}}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+		return resp.Result.(*mcp.CallToolResult)
+	}
+
+	result := call(`{"petId": "one"}`)
+	assert.True(t, result.IsError)
+	assert.False(t, called)
+	assert.Equal(t, "Invalid arguments for getPet:\n- petId: expected integer, got string\nFix the arguments and call the tool again.", result.Content[0].(mcp.TextContent).Text)
+
+	result = call(`{"petId": 1}`)
+	assert.False(t, result.IsError)
+	assert.True(t, called)
+}
+
 func TestMCPServer_Capabilities(t *testing.T) {
 	tests := []struct {
 		name     string